@@ -0,0 +1,105 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"qrobcis/pkgsmanager/internal/config"
+	"qrobcis/pkgsmanager/internal/models"
+	"qrobcis/pkgsmanager/internal/types/provider"
+	"qrobcis/pkgsmanager/internal/types/state"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	addProvider string
+	addVersion  string
+	addState    string
+	addSync     bool
+)
+
+// addCmd represents the config add command
+var addCmd = &cobra.Command{
+	Use:   "add <group> <name>",
+	Short: "Add a package to a group of the configuration file",
+	Long: `Add a package to a group of the configuration file, creating the group when
+it doesn't exist. Comments and the order of the other entries are kept.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		groupName, packageName := args[0], args[1]
+		pkgConfiguration, err := newPackageFromFlags(packageName)
+		if err != nil {
+			pterm.Error.Println(err)
+			os.Exit(1)
+		}
+
+		path := groupFile(initEditableConfiguration(), groupName)
+		err = editConfiguration(path, func(editor *config.Editor) error {
+			return editor.AddPackage(groupName, pkgConfiguration)
+		})
+		if err != nil {
+			pterm.Error.Println(err)
+			os.Exit(1)
+		}
+		pterm.Success.Printfln("Added %s to group %s in %s", packageName, groupName, path)
+
+		if addSync {
+			syncSinglePackage(groupName, packageName)
+		}
+	},
+}
+
+// newPackageFromFlags builds the package entry to add. Settings left to their
+// default are not written to the file.
+func newPackageFromFlags(packageName string) (pkgConfiguration *models.PackageConfiguration, err error) {
+	pkgConfiguration = &models.PackageConfiguration{Name: packageName, Version: addVersion}
+
+	if addProvider != "" {
+		pkgConfiguration.Provider = provider.ToProvider(addProvider)
+		if pkgConfiguration.Provider == provider.Unknown {
+			err = errors.New(fmt.Sprintf("Provider not supported: %s", addProvider))
+			return
+		}
+	}
+	if addState != "" {
+		pkgConfiguration.State = state.ToState(addState)
+		if pkgConfiguration.State == state.Unknown {
+			err = errors.New(fmt.Sprintf("State not supported: %s", addState))
+		}
+	}
+
+	return
+}
+
+func init() {
+	configCmd.AddCommand(addCmd)
+
+	addCmd.Flags().StringVarP(&addProvider, "provider", "p", "", "Provider of the package (default apt)")
+	addCmd.Flags().StringVarP(&addVersion, "version", "v", "", "Version to install")
+	addCmd.Flags().StringVar(&addState, "state", "", "State of the package: present, absent or purged (default present)")
+	addCmd.Flags().BoolVar(&addSync, "sync", false, "Install the package right away")
+}
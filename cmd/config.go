@@ -23,6 +23,16 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"qrobcis/pkgsmanager/internal/config"
+	"qrobcis/pkgsmanager/internal/models"
+	"slices"
+	"strings"
+
+	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 )
 
@@ -32,6 +42,97 @@ var configCmd = &cobra.Command{
 	Short: "Handle configuration file",
 }
 
+// initEditableConfiguration loads the configuration as initConfiguration does,
+// except that a file given with --config may not exist yet: editing it creates
+// it.
+func initEditableConfiguration() []*models.GroupConfiguration {
+	if cfgFile != "" {
+		if _, err := os.Stat(cfgFile); errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+	}
+
+	return initConfiguration()
+}
+
+// groupFile returns the configuration file declaring the group. A group that
+// doesn't exist yet goes to the most specific configuration file, or to the
+// user one when there is none.
+func groupFile(configuration []*models.GroupConfiguration, groupName string) (path string) {
+	// Origin is the last file declaring the group, the declaration in effect.
+	for _, group := range configuration {
+		if strings.EqualFold(group.Name, groupName) {
+			return group.Origin
+		}
+	}
+
+	if path = primaryConfigFile(); path != "" {
+		return
+	}
+	home, err := os.UserHomeDir()
+	cobra.CheckErr(err)
+
+	return filepath.Join(home, config.FileName)
+}
+
+// editConfiguration applies edit to the configuration file and saves it. The
+// file is loaded from then on if it was just created.
+func editConfiguration(path string, edit func(editor *config.Editor) error) (err error) {
+	editor, err := config.OpenEditor(path)
+	if err != nil {
+		return
+	}
+	if err = edit(editor); err != nil {
+		return
+	}
+	if err = editor.Save(); err != nil {
+		return
+	}
+
+	if !slices.Contains(configFiles, path) {
+		configFiles = append(configFiles, path)
+	}
+
+	return
+}
+
+// syncSinglePackage installs or removes a package of the configuration
+// according to its state, as sync would.
+func syncSinglePackage(groupName string, packageName string) {
+	providersMap := initProviders()
+	configuration := initConfiguration()
+	group, pkgConfiguration, err := findPackage(configuration, groupName, packageName)
+	if err != nil {
+		pterm.Error.Println(err)
+		os.Exit(1)
+	}
+
+	single := []*models.GroupConfiguration{{Name: group.Name, Packages: []*models.PackageConfiguration{pkgConfiguration}}}
+	usedProviders, unavailableProviders := checkProviders(providersMap, single)
+	if err, cmdErr := syncRepositories(providersMap, configuration, single); err != nil {
+		printPackageError(err, cmdErr)
+		os.Exit(1)
+	}
+	if err, cmdErr := syncPreferences(providersMap, configuration, single); err != nil {
+		printPackageError(err, cmdErr)
+		os.Exit(1)
+	}
+	for _, usedProvider := range usedProviders {
+		if err, cmdErr := providersMap[usedProvider].UpdateRegistry(); err != nil {
+			printPackageError(err, cmdErr)
+			os.Exit(1)
+		}
+	}
+
+	ctx := context.WithValue(context.Background(), "providers", providersMap)
+	ctx = context.WithValue(ctx, "unavailableProviders", unavailableProviders)
+	result := syncPackage(ctx, syncJob{group: group.Name, pkgConfiguration: pkgConfiguration})
+	printSyncResult(result)
+	if result.outcome == outcomeFailed {
+		os.Exit(1)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(configCmd)
 }
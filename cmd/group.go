@@ -0,0 +1,134 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"qrobcis/pkgsmanager/internal/config"
+	"qrobcis/pkgsmanager/internal/models"
+	"slices"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var groupRmForce bool
+
+// groupCmd represents the config group command
+var groupCmd = &cobra.Command{
+	Use:   "group",
+	Short: "Add or remove groups of the configuration file",
+}
+
+// groupAddCmd represents the config group add command
+var groupAddCmd = &cobra.Command{
+	Use:   "add <group>",
+	Short: "Add an empty group to the configuration file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		groupName := args[0]
+		configuration := initEditableConfiguration()
+		for _, group := range configuration {
+			if strings.EqualFold(group.Name, groupName) {
+				pterm.Error.Printfln("Group %s already exists in %s", group.Name, group.Origin)
+				os.Exit(1)
+			}
+		}
+
+		path := groupFile(configuration, groupName)
+		err := editConfiguration(path, func(editor *config.Editor) error {
+			return editor.AddGroup(groupName)
+		})
+		if err != nil {
+			pterm.Error.Println(err)
+			os.Exit(1)
+		}
+		pterm.Success.Printfln("Added group %s to %s", groupName, path)
+	},
+}
+
+// groupRmCmd represents the config group rm command
+var groupRmCmd = &cobra.Command{
+	Use:   "rm <group>",
+	Short: "Remove a group from the configuration file",
+	Long: `Remove a group from the configuration file. A group that still has packages
+is only removed with --force; its packages are left installed.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		groupName := args[0]
+		configuration := initConfiguration()
+
+		var err error
+		removed := false
+		for _, group := range configuration {
+			if !strings.EqualFold(group.Name, groupName) {
+				continue
+			}
+			if dependents := groupDependents(configuration, group.Name); len(dependents) > 0 {
+				err = errors.New(fmt.Sprintf("Group %s is a dependency of %s", group.Name, strings.Join(dependents, ", ")))
+				break
+			}
+			if len(group.Packages) > 0 && !groupRmForce {
+				err = errors.New(fmt.Sprintf("Group %s still has %d package(s), use --force to remove it anyway", group.Name, len(group.Packages)))
+				break
+			}
+
+			err = editConfiguration(group.Origin, func(editor *config.Editor) error {
+				return editor.RemoveGroup(group.Name)
+			})
+			removed = err == nil
+			if removed {
+				pterm.Success.Printfln("Removed group %s from %s", group.Name, group.Origin)
+			}
+		}
+		if err == nil && !removed {
+			err = errors.New(fmt.Sprintf("Group %s not found in the configuration", groupName))
+		}
+		if err != nil {
+			pterm.Error.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// groupDependents returns the groups depending on the group.
+func groupDependents(configuration []*models.GroupConfiguration, groupName string) (dependents []string) {
+	for _, group := range configuration {
+		if slices.Contains(group.DependsOn, groupName) {
+			dependents = append(dependents, group.Name)
+		}
+	}
+
+	return
+}
+
+func init() {
+	configCmd.AddCommand(groupCmd)
+	groupCmd.AddCommand(groupAddCmd)
+	groupCmd.AddCommand(groupRmCmd)
+
+	groupRmCmd.Flags().BoolVarP(&groupRmForce, "force", "f", false, "Remove the group even when it still has packages")
+}
@@ -0,0 +1,187 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"os"
+	"qrobcis/pkgsmanager/internal/config"
+	"qrobcis/pkgsmanager/internal/models"
+	"qrobcis/pkgsmanager/internal/providers"
+	"qrobcis/pkgsmanager/internal/types/provider"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importProviders []string
+	importAll       bool
+	importNoVersion bool
+)
+
+// importCmd represents the config import command
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Add the packages already installed on the machine to the configuration file",
+	Long: `Read the packages installed on the machine and add the ones picked
+interactively to the configuration file, in one group per provider: manually
+installed apt packages, npm globals, gems, go binaries and snaps. Packages
+already in the configuration are left out, and the installed versions are
+written as pins unless --no-version is given. Pinned apt packages are written
+with hold: false, or sync would hold every imported package with apt-mark and
+the system would stop receiving upgrades.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		providersMap := initProviders()
+		configuration := initEditableConfiguration()
+
+		imported := 0
+		for _, providerName := range importProviders {
+			packageProvider, found := providersMap[provider.ToProvider(providerName)]
+			lister, listable := packageProvider.(providers.Lister)
+			if !found || !listable {
+				pterm.Warning.Printfln("Provider %s can't list installed packages, skipping", providerName)
+				continue
+			}
+			if !packageProvider.IsAvailable() {
+				pterm.Warning.Printfln("Provider %s is not installed, skipping", providerName)
+				continue
+			}
+
+			installed, err := lister.ListInstalled()
+			if err != nil {
+				pterm.Error.Printfln("Failed to list %s packages: %s", providerName, err)
+				continue
+			}
+			candidates := newPackages(configuration, provider.ToProvider(providerName), installed)
+			if len(candidates) == 0 {
+				pterm.Info.Printfln("No new %s package to import", providerName)
+				continue
+			}
+
+			selected := selectPackages(providerName, candidates)
+			if len(selected) == 0 {
+				continue
+			}
+			if err = importPackages(configuration, providerName, selected); err != nil {
+				pterm.Error.Println(err)
+				os.Exit(1)
+			}
+			imported += len(selected)
+		}
+
+		pterm.Success.Printfln("Imported %d package(s).", imported)
+	},
+}
+
+// newPackages returns the installed packages the configuration doesn't
+// declare yet with the provider.
+func newPackages(configuration []*models.GroupConfiguration, providerName provider.Provider, installed []providers.InstalledPackage) (candidates []providers.InstalledPackage) {
+	declared := make(map[string]bool)
+	for _, group := range configuration {
+		for _, pkgConfiguration := range group.Packages {
+			if pkgConfiguration.Provider == providerName {
+				declared[pkgConfiguration.Name] = true
+			}
+		}
+	}
+
+	for _, installedPackage := range installed {
+		if !declared[installedPackage.Name] {
+			candidates = append(candidates, installedPackage)
+		}
+	}
+
+	return
+}
+
+// selectPackages lets the user pick the packages to import, all of them being
+// selected at first. With --all every package is kept without asking.
+func selectPackages(providerName string, candidates []providers.InstalledPackage) (selected []providers.InstalledPackage) {
+	if importAll {
+		return candidates
+	}
+
+	options := make([]string, 0, len(candidates))
+	byOption := make(map[string]providers.InstalledPackage)
+	for _, candidate := range candidates {
+		option := candidate.Name
+		if candidate.Version != "" {
+			option += " " + candidate.Version
+		}
+		options = append(options, option)
+		byOption[option] = candidate
+	}
+
+	chosen, err := pterm.DefaultInteractiveMultiselect.
+		WithOptions(options).
+		WithDefaultOptions(options).
+		WithMaxHeight(15).
+		Show("Select the " + providerName + " packages to keep")
+	if err != nil {
+		pterm.Error.Printfln("Failed to select %s packages: %s", providerName, err)
+		return
+	}
+
+	for _, option := range chosen {
+		selected = append(selected, byOption[option])
+	}
+
+	return
+}
+
+// importPackages adds the packages to the group named after their provider.
+func importPackages(configuration []*models.GroupConfiguration, providerName string, selected []providers.InstalledPackage) (err error) {
+	path := groupFile(configuration, providerName)
+	err = editConfiguration(path, func(editor *config.Editor) (err error) {
+		for _, installedPackage := range selected {
+			pkgConfiguration := &models.PackageConfiguration{Name: installedPackage.Name, Provider: provider.ToProvider(providerName)}
+			if !importNoVersion {
+				pkgConfiguration.Version = installedPackage.Version
+			}
+			if pkgConfiguration.Provider == provider.APT && pkgConfiguration.Version != "" {
+				// An exact version holds apt packages by default, the
+				// whole base system would no longer be upgraded.
+				hold := false
+				pkgConfiguration.Apt.Hold = &hold
+			}
+			if err = editor.AddPackage(providerName, pkgConfiguration); err != nil {
+				return
+			}
+		}
+
+		return
+	})
+	if err == nil {
+		pterm.Info.Printfln("Added %d %s package(s) to %s", len(selected), providerName, path)
+	}
+
+	return
+}
+
+func init() {
+	configCmd.AddCommand(importCmd)
+
+	importCmd.Flags().StringSliceVarP(&importProviders, "provider", "p", []string{string(provider.APT), string(provider.NPM), string(provider.Gem), string(provider.Golang), string(provider.Snap)}, "Providers to import the packages of")
+	importCmd.Flags().BoolVarP(&importAll, "all", "a", false, "Import every package without asking")
+	importCmd.Flags().BoolVar(&importNoVersion, "no-version", false, "Don't pin the installed versions")
+}
@@ -26,6 +26,8 @@ import (
 	"github.com/pterm/pterm"
 	"github.com/spf13/viper"
 	"os"
+	"path/filepath"
+	"qrobcis/pkgsmanager/internal/config"
 	"qrobcis/pkgsmanager/internal/models"
 	"qrobcis/pkgsmanager/internal/types/provider"
 
@@ -37,14 +39,16 @@ var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize a new configuration file if not present.",
 	Run: func(cmd *cobra.Command, args []string) {
-		home, err := os.UserHomeDir()
-		spinner, _ := pterm.DefaultSpinner.Start("Initializing configuration file at: " + pterm.Red(" ", home, "/.pkgsmanager.yaml"))
-		cobra.CheckErr(err)
-		viper.AddConfigPath(home)
+		configurationPath := cfgFile
+		if configurationPath == "" {
+			home, err := os.UserHomeDir()
+			cobra.CheckErr(err)
+			configurationPath = filepath.Join(home, config.FileName)
+		}
+		spinner, _ := pterm.DefaultSpinner.Start("Initializing configuration file at: " + pterm.Red(" ", configurationPath))
 		viper.SetConfigType("yaml")
-		viper.SetConfigName(".pkgsmanager")
 		viper.Set("default", [...]models.PackageConfiguration{{Name: "git", Provider: provider.APT}, {Name: "vim", Provider: provider.APT}})
-		err = viper.SafeWriteConfig()
+		err := viper.SafeWriteConfigAs(configurationPath)
 		if err != nil {
 			spinner.Info()
 			pterm.Info.Println(err)
@@ -0,0 +1,200 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"qrobcis/pkgsmanager/internal/lock"
+	"qrobcis/pkgsmanager/internal/models"
+	"qrobcis/pkgsmanager/internal/providers"
+	"qrobcis/pkgsmanager/internal/types/provider"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// lockCmd represents the lock command
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Handle the lockfile recording the installed versions",
+}
+
+// lockUpdateCmd represents the lock update command
+var lockUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Record the installed version of every package in the lockfile",
+	Long: `Query the providers for the version of every package of the configuration
+file and write them to the lockfile, next to the configuration file. Nothing
+is installed: packages that are not installed keep their previous entry.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		providersMap := initProviders()
+		configuration := initConfiguration()
+
+		if err := writeLockfile(providersMap, configuration); err != nil {
+			pterm.Error.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// lockfilePath returns the path of the lockfile, next to the most specific
+// configuration file.
+func lockfilePath() (path string, err error) {
+	if primaryConfigFile() == "" {
+		err = errors.New("No configuration file found, the lockfile is written next to it")
+		return
+	}
+	path = lock.PathFor(primaryConfigFile())
+
+	return
+}
+
+// writeLockfile records the installed version of the packages of the
+// configuration. Packages that can't be queried, because they are not
+// installed or their provider is missing, keep the entry of the previous
+// lockfile so that one machine lacking a provider doesn't drop them.
+func writeLockfile(providersMap map[provider.Provider]providers.PackageProvider, configuration []*models.GroupConfiguration) (err error) {
+	path, err := lockfilePath()
+	if err != nil {
+		return
+	}
+
+	previous, err := lock.Read(path)
+	if errors.Is(err, os.ErrNotExist) {
+		previous, err = &lock.Lockfile{}, nil
+	}
+	if err != nil {
+		return
+	}
+
+	lockfile := &lock.Lockfile{}
+	var unlocked []string
+	for _, group := range configuration {
+		for _, pkgConfiguration := range group.Packages {
+			if pkgConfiguration.State.IsRemoved() {
+				continue
+			}
+
+			// Packages skipped on this machine keep the entry written on
+			// the machines they are installed on.
+			if lockedPackage, found := lockPackage(providersMap, group, pkgConfiguration); found {
+				lockfile.Packages = append(lockfile.Packages, lockedPackage)
+			} else if lockedPackage, found = previous.Find(group.Name, pkgConfiguration.Name); found && lockedPackage.Provider == pkgConfiguration.Provider {
+				lockfile.Packages = append(lockfile.Packages, lockedPackage)
+			} else if pkgConfiguration.SkipReason == "" {
+				unlocked = append(unlocked, pkgConfiguration.Name)
+			}
+		}
+	}
+
+	if err = lockfile.Write(path); err != nil {
+		return
+	}
+	pterm.Info.Printfln("Wrote %d package(s) to %s", len(lockfile.Packages), path)
+	if len(unlocked) > 0 {
+		pterm.Warning.Printfln("Packages not installed, left out of the lockfile: %s", strings.Join(unlocked, ", "))
+	}
+
+	return
+}
+
+func lockPackage(providersMap map[provider.Provider]providers.PackageProvider, group *models.GroupConfiguration, pkgConfiguration *models.PackageConfiguration) (lockedPackage lock.LockedPackage, found bool) {
+	packageProvider, found := providersMap[pkgConfiguration.Provider]
+	if !found || packageProvider.MissingCommand(pkgConfiguration) != "" || pkgConfiguration.SkipReason != "" || pkgConfiguration.State.IsRemoved() {
+		return lockedPackage, false
+	}
+
+	installed, err := packageProvider.IsInstalled(pkgConfiguration)
+	if err != nil || !installed {
+		return lockedPackage, false
+	}
+	locked, err := providers.LockPackage(packageProvider, pkgConfiguration)
+	if err != nil || locked.Version == "" {
+		return lockedPackage, false
+	}
+
+	lockedPackage = lock.LockedPackage{
+		Group:        group.Name,
+		Name:         pkgConfiguration.Name,
+		Provider:     pkgConfiguration.Provider,
+		Version:      locked.Version,
+		Repository:   locked.Repository,
+		Architecture: locked.Architecture,
+	}
+
+	return lockedPackage, true
+}
+
+// applyLockfile replaces the version of every package of the configuration by
+// the one of the lockfile, so that sync installs exactly those. Packages
+// missing from the lockfile are an error: the lockfile has to be updated
+// first.
+func applyLockfile(providersMap map[provider.Provider]providers.PackageProvider, configuration []*models.GroupConfiguration) (err error) {
+	path, err := lockfilePath()
+	if err != nil {
+		return
+	}
+
+	lockfile, err := lock.Read(path)
+	if errors.Is(err, os.ErrNotExist) {
+		err = errors.New(fmt.Sprintf("No lockfile at %s, run pkgsmanager lock update first", path))
+	}
+	if err != nil {
+		return
+	}
+
+	var unlocked []string
+	for _, group := range configuration {
+		for _, pkgConfiguration := range group.Packages {
+			if pkgConfiguration.State.IsRemoved() || pkgConfiguration.SkipReason != "" {
+				continue
+			}
+
+			if packageProvider, found := providersMap[pkgConfiguration.Provider]; found && !packageProvider.CanPinVersion(pkgConfiguration) {
+				pterm.Warning.Printfln("Provider %s can't install a given version of %s, it is installed from its configuration", pkgConfiguration.Provider, pkgConfiguration.Name)
+				continue
+			}
+
+			lockedPackage, found := lockfile.Find(group.Name, pkgConfiguration.Name)
+			if !found || lockedPackage.Provider != pkgConfiguration.Provider {
+				unlocked = append(unlocked, pkgConfiguration.Name)
+				continue
+			}
+			pkgConfiguration.Version = lockedPackage.Version
+		}
+	}
+
+	if len(unlocked) > 0 {
+		err = errors.New(fmt.Sprintf("The lockfile %s is out of date for: %s, run pkgsmanager lock update", path, strings.Join(unlocked, ", ")))
+	}
+
+	return
+}
+
+func init() {
+	rootCmd.AddCommand(lockCmd)
+	lockCmd.AddCommand(lockUpdateCmd)
+}
@@ -0,0 +1,111 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"qrobcis/pkgsmanager/internal/config"
+	"qrobcis/pkgsmanager/internal/models"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var mvGroup string
+
+// mvCmd represents the config mv command
+var mvCmd = &cobra.Command{
+	Use:   "mv <name> <group>",
+	Short: "Move a package to another group of the configuration",
+	Long: `Move a package to another group, creating the group when it doesn't exist.
+The package entry is moved as is, comments included, to the file declaring the
+target group. A package with dependencies in its group, either way, can't be
+moved since dependsOn only names packages of the same group.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		packageName, groupName := args[0], args[1]
+		configuration := initConfiguration()
+
+		group, pkgConfiguration, err := findPackage(configuration, mvGroup, packageName)
+		if err == nil && !strings.EqualFold(group.Name, groupName) {
+			err = checkMovable(group, pkgConfiguration)
+		}
+		if err != nil {
+			pterm.Error.Println(err)
+			os.Exit(1)
+		}
+
+		if err = movePackage(packageName, group.Origin, group.Name, groupFile(configuration, groupName), groupName); err != nil {
+			pterm.Error.Println(err)
+			os.Exit(1)
+		}
+		pterm.Success.Printfln("Moved %s from group %s to group %s", packageName, group.Name, groupName)
+	},
+}
+
+// checkMovable fails when the package depends on packages of its group, or
+// packages of its group depend on it: dependsOn only names packages of the
+// same group, so these dependencies would point to unknown packages once the
+// package is moved.
+func checkMovable(group *models.GroupConfiguration, pkgConfiguration *models.PackageConfiguration) (err error) {
+	if len(pkgConfiguration.DependsOn) > 0 {
+		return errors.New(fmt.Sprintf("Package %s depends on %s in group %s, drop its dependsOn before moving it", pkgConfiguration.Name, strings.Join(pkgConfiguration.DependsOn, ", "), group.Name))
+	}
+	if dependents := packageDependents(group, pkgConfiguration.Name); len(dependents) > 0 {
+		return errors.New(fmt.Sprintf("Package %s is a dependency of %s in group %s, drop it from their dependsOn before moving it", pkgConfiguration.Name, strings.Join(dependents, ", "), group.Name))
+	}
+
+	return
+}
+
+// movePackage moves a package entry between groups, which may be declared in
+// different files. The target file is saved first so that a failure never
+// loses the entry.
+func movePackage(packageName string, fromPath string, fromGroup string, toPath string, toGroup string) (err error) {
+	if fromPath == toPath {
+		return editConfiguration(fromPath, func(editor *config.Editor) error {
+			return editor.MovePackage(packageName, fromGroup, editor, toGroup)
+		})
+	}
+
+	target, err := config.OpenEditor(toPath)
+	if err != nil {
+		return
+	}
+	return editConfiguration(fromPath, func(editor *config.Editor) (err error) {
+		if err = editor.MovePackage(packageName, fromGroup, target, toGroup); err != nil {
+			return
+		}
+
+		return target.Save()
+	})
+}
+
+func init() {
+	configCmd.AddCommand(mvCmd)
+
+	mvCmd.Flags().StringVarP(&mvGroup, "from", "f", "", "Group the package belongs to, when declared in several groups")
+}
@@ -0,0 +1,92 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"os"
+	"qrobcis/pkgsmanager/internal/config"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// pathCmd represents the config path command
+var pathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Show the configuration files loaded and the file each group comes from",
+	Long: `List the configuration files in the order they are loaded: the files of
+` + config.SystemDirectory + `, the user file in the home directory and the
+project file in the working directory, or only the file given with --config.
+A group declared in several files comes from the last one.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(configFiles) == 0 {
+			pterm.Warning.Println("No configuration file found, run pkgsmanager config init to create one")
+			return
+		}
+
+		pterm.DefaultSection.Println("Configuration files")
+		var fileItems []pterm.BulletListItem
+		for _, path := range configFiles {
+			fileItems = append(fileItems, pterm.BulletListItem{Level: 0, Text: path})
+		}
+		_ = pterm.DefaultBulletList.WithItems(fileItems).Render()
+
+		groups, err := config.LoadAll(configFiles)
+		if err != nil {
+			pterm.Error.Println(err)
+			os.Exit(1)
+		}
+
+		pterm.DefaultSection.Println("Groups")
+		tableData := pterm.TableData{{"Group", "Packages", "File"}}
+		for _, group := range groups {
+			tableData = append(tableData, []string{group.Name, pterm.Sprint(len(group.Packages)), group.Origin})
+		}
+		_ = pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+
+		profiles, err := config.LoadProfiles(configFiles)
+		if err != nil {
+			pterm.Error.Println(err)
+			os.Exit(1)
+		}
+		if len(profiles.Groups) == 0 {
+			return
+		}
+
+		pterm.DefaultSection.Println("Profiles")
+		tableData = pterm.TableData{{"Profile", "Groups"}}
+		for _, name := range profiles.Names() {
+			label := name
+			if name == profiles.Default {
+				label += " (default)"
+			}
+			tableData = append(tableData, []string{label, strings.Join(profiles.Groups[name], ", ")})
+		}
+		_ = pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+	},
+}
+
+func init() {
+	configCmd.AddCommand(pathCmd)
+}
@@ -0,0 +1,248 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"qrobcis/pkgsmanager/internal/models"
+	"qrobcis/pkgsmanager/internal/providers"
+	"qrobcis/pkgsmanager/internal/types/provider"
+	"slices"
+	"strings"
+
+	"github.com/pterm/pterm"
+)
+
+const (
+	actionRepositories   = "sync repositories"
+	actionPreferences    = "sync preferences"
+	actionUpdateRegistry = "update registry"
+	actionCleanRegistry  = "clean registry"
+	actionInstall        = "install"
+	actionRemove         = "remove"
+	actionHold           = "change hold"
+	actionUpToDate       = "up to date"
+	actionAbsent         = "absent"
+	actionSkipped        = "skipped"
+	actionError          = "error"
+)
+
+// plannedAction is what sync would do for one package, or for a provider
+// registry, along with the exact steps the provider would run.
+type plannedAction struct {
+	Group    string                  `json:"group,omitempty"`
+	Package  string                  `json:"package,omitempty"`
+	Provider provider.Provider       `json:"provider"`
+	Action   string                  `json:"action"`
+	Steps    []providers.PlannedStep `json:"steps,omitempty"`
+	Error    string                  `json:"error,omitempty"`
+	Reason   string                  `json:"reason,omitempty"`
+}
+
+// planSync resolves what sync would do for the selected groups without changing
+// anything: providers are switched to dry-run mode, so their commands are
+// recorded instead of being run. Repositories and pins are kept as long as a
+// package of the configuration refers to them.
+func planSync(providersMap map[provider.Provider]providers.PackageProvider, configuration []*models.GroupConfiguration, selected []*models.GroupConfiguration) (plan []plannedAction) {
+	for _, packageProvider := range providersMap {
+		packageProvider.SetDryRun(true)
+	}
+
+	if err, _ := syncRepositories(providersMap, configuration, selected); err != nil {
+		plan = append(plan, plannedAction{Provider: provider.APT, Action: actionError, Error: err.Error()})
+	}
+	plan = append(plan, takeProviderPlans(providersMap, actionRepositories)...)
+	if err, _ := syncPreferences(providersMap, configuration, selected); err != nil {
+		plan = append(plan, plannedAction{Provider: provider.APT, Action: actionError, Error: err.Error()})
+	}
+	plan = append(plan, takeProviderPlans(providersMap, actionPreferences)...)
+
+	used := usedProviders(providersMap, selected)
+	unavailable := make(map[provider.Provider]bool)
+	for _, usedProvider := range used {
+		if !providersMap[usedProvider].IsAvailable() {
+			unavailable[usedProvider] = true
+			plan = append(plan, plannedAction{Provider: usedProvider, Action: actionError, Error: "provider is not installed"})
+			continue
+		}
+		_, _ = providersMap[usedProvider].UpdateRegistry()
+		plan = append(plan, plannedAction{Provider: usedProvider, Action: actionUpdateRegistry, Steps: providersMap[usedProvider].TakePlan()})
+	}
+
+	for _, group := range selected {
+		for _, pkgConfiguration := range group.Packages {
+			action := plannedAction{Package: pkgConfiguration.Name, Provider: pkgConfiguration.Provider, Action: actionSkipped}
+			if pkgConfiguration.SkipReason != "" {
+				action.Reason = pkgConfiguration.SkipReason
+			} else if !unavailable[pkgConfiguration.Provider] {
+				action = planPackage(providersMap, pkgConfiguration)
+			}
+			action.Group = group.Name
+			plan = append(plan, action)
+		}
+	}
+
+	for _, usedProvider := range used {
+		if unavailable[usedProvider] {
+			continue
+		}
+		_, _ = providersMap[usedProvider].CleanRegistry()
+		plan = append(plan, plannedAction{Provider: usedProvider, Action: actionCleanRegistry, Steps: providersMap[usedProvider].TakePlan()})
+	}
+
+	return
+}
+
+// takeProviderPlans returns an action with the steps each provider recorded,
+// for the providers that recorded some.
+func takeProviderPlans(providersMap map[provider.Provider]providers.PackageProvider, action string) (plan []plannedAction) {
+	for _, providerName := range slices.Sorted(maps.Keys(providersMap)) {
+		if steps := providersMap[providerName].TakePlan(); len(steps) > 0 {
+			plan = append(plan, plannedAction{Provider: providerName, Action: action, Steps: steps})
+		}
+	}
+
+	return
+}
+
+func planPackage(providersMap map[provider.Provider]providers.PackageProvider, pkgConfiguration *models.PackageConfiguration) (action plannedAction) {
+	action = plannedAction{Package: pkgConfiguration.Name, Provider: pkgConfiguration.Provider}
+
+	packageProvider, found := providersMap[pkgConfiguration.Provider]
+	if !found {
+		action.Action = actionError
+		action.Error = fmt.Sprintf("Provider not supported: %s", pkgConfiguration.Provider)
+		return
+	}
+	if command := packageProvider.MissingCommand(pkgConfiguration); command != "" {
+		action.Action = actionSkipped
+		action.Reason = command + " is not installed"
+		return
+	}
+
+	var err error
+	if pkgConfiguration.State.IsRemoved() {
+		var installed bool
+		if installed, err = packageProvider.IsInstalled(pkgConfiguration); err == nil && installed {
+			action.Action = actionRemove
+			err, _ = packageProvider.RemovePackage(pkgConfiguration)
+		} else if err == nil {
+			action.Action = actionAbsent
+		}
+	} else {
+		// Like sync, a failed query falls back to installing the package.
+		if satisfied, _ := providers.IsSatisfied(packageProvider, pkgConfiguration); satisfied {
+			action.Action = actionUpToDate
+		} else {
+			action.Action = actionInstall
+			err, _ = packageProvider.InstallPackage(pkgConfiguration)
+		}
+
+		var holdChanged bool
+		if err == nil {
+			holdChanged, err, _ = providers.SyncHold(packageProvider, pkgConfiguration)
+		}
+		if holdChanged && action.Action == actionUpToDate {
+			action.Action = actionHold
+		}
+	}
+
+	action.Steps = packageProvider.TakePlan()
+	if err != nil {
+		action.Action = actionError
+		action.Error = err.Error()
+	}
+
+	return
+}
+
+func printPlan(plan []plannedAction, output string) (err error) {
+	switch output {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(plan)
+	case "text":
+		printPlanText(plan)
+	default:
+		err = errors.New(fmt.Sprintf("Output format not supported: %s", output))
+	}
+
+	return
+}
+
+func printPlanText(plan []plannedAction) {
+	pterm.DefaultSection.Println("Sync plan (nothing will be executed)")
+
+	changes := 0
+	for _, action := range plan {
+		paddedProvider := formatProvider(&models.PackageConfiguration{Provider: action.Provider})
+		title := action.Action
+		if action.Package != "" {
+			title = action.Package + " " + pterm.Gray("("+action.Group+")") + ": " + action.Action
+		}
+
+		switch action.Action {
+		case actionUpToDate, actionAbsent, actionSkipped:
+			if action.Reason != "" {
+				title += ": " + action.Reason
+			}
+			pterm.FgGray.Println("| " + paddedProvider + "| " + title)
+		case actionError:
+			pterm.FgRed.Println("| " + paddedProvider + "| " + title + ": " + action.Error)
+		default:
+			pterm.Println("| " + paddedProvider + "| " + title)
+		}
+
+		for _, step := range action.Steps {
+			if action.Package != "" {
+				changes += 1
+			}
+			printPlannedStep(step)
+		}
+	}
+
+	pterm.Println()
+	pterm.Info.Printfln("%d package step(s) would be executed.", changes)
+}
+
+func printPlannedStep(step providers.PlannedStep) {
+	if step.URL != "" {
+		pterm.Println("    fetch " + step.URL + " -> " + step.File)
+	} else if step.File != "" && step.Content != "" {
+		pterm.Println("    write " + step.File + ":")
+		for _, line := range strings.Split(strings.TrimRight(step.Content, "\n"), "\n") {
+			pterm.Println("      " + pterm.Gray(line))
+		}
+	} else if step.File != "" && len(step.Command) == 0 {
+		pterm.Println("    delete " + step.File)
+	}
+
+	if len(step.Command) > 0 {
+		pterm.Println("    $ " + strings.Join(step.Command, " "))
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"qrobcis/pkgsmanager/internal/config"
+	"qrobcis/pkgsmanager/internal/models"
+	"slices"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	removeGroup string
+	removeForce bool
+)
+
+// removeCmd represents the remove command
+var removeCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a package and drop it from the configuration file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		packageName := args[0]
+		providersMap := initProviders()
+		configuration := initConfiguration()
+		ctx := context.WithValue(context.Background(), "providers", providersMap)
+
+		group, pkgConfiguration, err := findPackage(configuration, removeGroup, packageName)
+		if err == nil && !removeForce {
+			err = checkPackageDependents(group, packageName)
+		}
+		if err != nil {
+			pterm.Error.Println(err)
+			os.Exit(1)
+		}
+
+		uninstallPackage(ctx, pkgConfiguration)
+
+		err = editConfiguration(group.Origin, func(editor *config.Editor) error {
+			return editor.RemovePackage(group.Name, packageName)
+		})
+		if err != nil {
+			pterm.Error.Println(err)
+			os.Exit(1)
+		}
+		pterm.Success.Printfln("Removed %s from group %s", packageName, group.Name)
+	},
+}
+
+// uninstallPackage removes the package from the system and exits on failure.
+func uninstallPackage(ctx context.Context, pkgConfiguration *models.PackageConfiguration) {
+	absent, err, cmdErr := removePackage(ctx, pkgConfiguration)
+	if err != nil {
+		printPackageError(err, cmdErr)
+		os.Exit(1)
+	}
+
+	paddedProvider := formatProvider(pkgConfiguration)
+	if absent {
+		pterm.FgGray.Println("| " + paddedProvider + "| Package " + pkgConfiguration.Name + " is absent")
+	} else {
+		pterm.FgGreen.Println("| " + paddedProvider + "| Removed package " + pkgConfiguration.Name)
+	}
+}
+
+// findPackage looks a package up by name, restricted to groupName when set.
+// A name declared in several groups is an error unless the group is given.
+func findPackage(configuration []*models.GroupConfiguration, groupName string, packageName string) (group *models.GroupConfiguration, pkgConfiguration *models.PackageConfiguration, err error) {
+	var matchingGroups []string
+	for _, candidate := range configuration {
+		if groupName != "" && !strings.EqualFold(candidate.Name, groupName) {
+			continue
+		}
+		if candidatePackage, found := candidate.Package(packageName); found {
+			group = candidate
+			pkgConfiguration = candidatePackage
+			matchingGroups = append(matchingGroups, candidate.Name)
+		}
+	}
+
+	if len(matchingGroups) == 0 {
+		err = errors.New(fmt.Sprintf("Package %s not found in the configuration", packageName))
+	} else if len(matchingGroups) > 1 {
+		err = errors.New(fmt.Sprintf("Package %s is declared in several groups (%s), use --group to pick one", packageName, strings.Join(matchingGroups, ", ")))
+	}
+
+	return
+}
+
+// packageDependents returns the packages of the group depending on the
+// package. Packages only depend on packages of their own group.
+func packageDependents(group *models.GroupConfiguration, packageName string) (dependents []string) {
+	for _, pkgConfiguration := range group.Packages {
+		if slices.Contains(pkgConfiguration.DependsOn, packageName) {
+			dependents = append(dependents, pkgConfiguration.Name)
+		}
+	}
+
+	return
+}
+
+// checkPackageDependents fails when other packages depend on the package.
+func checkPackageDependents(group *models.GroupConfiguration, packageName string) (err error) {
+	if dependents := packageDependents(group, packageName); len(dependents) > 0 {
+		err = errors.New(fmt.Sprintf("Package %s is a dependency of %s, use --force to remove it anyway", packageName, strings.Join(dependents, ", ")))
+	}
+
+	return
+}
+
+func init() {
+	rootCmd.AddCommand(removeCmd)
+
+	removeCmd.Flags().StringVarP(&removeGroup, "group", "g", "", "Group the package belongs to, when declared in several groups")
+	removeCmd.Flags().BoolVarP(&removeForce, "force", "f", false, "Remove the package even when other packages depend on it")
+}
@@ -0,0 +1,84 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"qrobcis/pkgsmanager/internal/config"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rmGroup string
+	rmSync  bool
+	rmForce bool
+)
+
+// rmCmd represents the config rm command
+var rmCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Remove a package from the configuration file",
+	Long: `Remove a package from the configuration file, leaving it installed unless
+--sync is given. Comments and the order of the other entries are kept.
+A package other packages depend on is only removed with --force, which also
+drops it from their dependsOn.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		packageName := args[0]
+		configuration := initConfiguration()
+
+		group, pkgConfiguration, err := findPackage(configuration, rmGroup, packageName)
+		if err == nil && !rmForce {
+			err = checkPackageDependents(group, packageName)
+		}
+		if err != nil {
+			pterm.Error.Println(err)
+			os.Exit(1)
+		}
+
+		if rmSync {
+			ctx := context.WithValue(context.Background(), "providers", initProviders())
+			uninstallPackage(ctx, pkgConfiguration)
+		}
+
+		err = editConfiguration(group.Origin, func(editor *config.Editor) error {
+			return editor.RemovePackage(group.Name, packageName)
+		})
+		if err != nil {
+			pterm.Error.Println(err)
+			os.Exit(1)
+		}
+		pterm.Success.Printfln("Removed %s from group %s in %s", packageName, group.Name, group.Origin)
+	},
+}
+
+func init() {
+	configCmd.AddCommand(rmCmd)
+
+	rmCmd.Flags().StringVarP(&rmGroup, "group", "g", "", "Group the package belongs to, when declared in several groups")
+	rmCmd.Flags().BoolVar(&rmSync, "sync", false, "Uninstall the package right away")
+	rmCmd.Flags().BoolVarP(&rmForce, "force", "f", false, "Remove the package even when other packages depend on it")
+}
@@ -24,12 +24,17 @@ package cmd
 
 import (
 	"os"
+	"qrobcis/pkgsmanager/internal/config"
+	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
-var cfgFile string
+var (
+	cfgFile        string
+	configFiles    []string
+	commandTimeout time.Duration
+)
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -52,25 +57,32 @@ func Execute() {
 
 func init() {
 	cobra.OnInitialize(initConfig)
+
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file, replacing the system, user and project ones (default is $HOME/.pkgsmanager.yaml)")
+	rootCmd.PersistentFlags().DurationVar(&commandTimeout, "command-timeout", 0, "Kill the provider commands running longer than this duration, such as 10m (default is no limit)")
 }
 
-// initConfig reads in config file and ENV variables if set.
+// initConfig lists the configuration files to load, from the least to the
+// most specific. The groups of a file replace the same-named groups of the
+// files before it.
 func initConfig() {
 	if cfgFile != "" {
 		// Use config file from the flag.
-		viper.SetConfigFile(cfgFile)
-	} else {
-		// Find home directory.
-		home, err := os.UserHomeDir()
-		cobra.CheckErr(err)
-
-		// Search config in home directory with name ".pkgsmanager" (without extension).
-		viper.AddConfigPath(home)
-		viper.SetConfigType("yaml")
-		viper.SetConfigName(".pkgsmanager")
+		configFiles = []string{cfgFile}
+		return
 	}
 
-	// If a config file is found, read it in.
-	if err := viper.ReadInConfig(); err == nil {
+	var err error
+	configFiles, err = config.Discover()
+	cobra.CheckErr(err)
+}
+
+// primaryConfigFile returns the most specific configuration file, the one the
+// lockfile is written next to, or an empty string when there is none.
+func primaryConfigFile() string {
+	if len(configFiles) == 0 {
+		return ""
 	}
+
+	return configFiles[len(configFiles)-1]
 }
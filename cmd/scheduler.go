@@ -0,0 +1,321 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"qrobcis/pkgsmanager/internal/models"
+	"qrobcis/pkgsmanager/internal/providers"
+	"qrobcis/pkgsmanager/internal/types/provider"
+	"slices"
+	"strings"
+
+	"github.com/pterm/pterm"
+)
+
+type packageOutcome int
+
+const (
+	outcomeInstalled packageOutcome = iota
+	outcomeRemoved
+	outcomeUpToDate
+	outcomeAbsent
+	outcomeSkipped
+	outcomeFailed
+)
+
+// syncJob is a package to synchronize along with the group declaring it and
+// the jobs, by index, it has to wait for.
+type syncJob struct {
+	group            string
+	pkgConfiguration *models.PackageConfiguration
+	dependencies     []int
+}
+
+type syncResult struct {
+	syncJob
+	outcome packageOutcome
+	err     error
+	cmdErr  error
+}
+
+// newSyncJobs lists the packages of the ordered configuration. A package waits
+// for the packages it depends on and for every package of the groups its
+// group depends on.
+func newSyncJobs(configuration []*models.GroupConfiguration) (jobs []syncJob) {
+	groupJobs := make(map[string][]int)
+	for _, group := range configuration {
+		packageJobs := make(map[string]int)
+		for _, pkgConfiguration := range group.Packages {
+			job := syncJob{group: group.Name, pkgConfiguration: pkgConfiguration}
+			for _, dependsOnGroup := range group.DependsOn {
+				job.dependencies = append(job.dependencies, groupJobs[dependsOnGroup]...)
+			}
+			for _, dependsOnPackage := range pkgConfiguration.DependsOn {
+				job.dependencies = append(job.dependencies, packageJobs[dependsOnPackage])
+			}
+
+			packageJobs[pkgConfiguration.Name] = len(jobs)
+			groupJobs[group.Name] = append(groupJobs[group.Name], len(jobs))
+			jobs = append(jobs, job)
+		}
+	}
+
+	return
+}
+
+// runSyncJobs synchronizes the packages, running at most jobs of them at the
+// same time. Jobs are started in order once their dependencies are done; the
+// ones whose dependencies failed are skipped. Packages whose providers take
+// the same system-wide lock, such as apt and deb which both take the dpkg one,
+// are synchronized one after another, as are the packages managed with sudo
+// so that its password prompts don't overlap. Results are returned in the
+// order of syncJobs.
+func runSyncJobs(ctx context.Context, syncJobs []syncJob, jobs int) (results []syncResult) {
+	if jobs < 1 {
+		jobs = 1
+	}
+	providersMap := ctx.Value("providers").(map[provider.Provider]providers.PackageProvider)
+
+	progress, _ := pterm.DefaultProgressbar.WithRemoveWhenDone(true).WithTotal(len(syncJobs)).WithTitle("Synchronizing packages").Start()
+	results = make([]syncResult, len(syncJobs))
+	done := make([]bool, len(syncJobs))
+	started := make([]bool, len(syncJobs))
+	busyLocks := make(map[string]bool)
+	jobLocks := make([][]string, len(syncJobs))
+	var running []string
+	finished := make(chan int)
+
+	complete := func(index int) {
+		done[index] = true
+		printSyncResult(results[index])
+		progress.UpdateTitle("Synchronizing " + strings.Join(running, ", "))
+		progress.Increment()
+	}
+
+	for completed := 0; completed < len(syncJobs); {
+		for index, job := range syncJobs {
+			if len(running) >= jobs {
+				break
+			}
+			if started[index] || !allDone(job.dependencies, done) {
+				continue
+			}
+
+			if failed := failedDependency(job.dependencies, results); failed != nil {
+				started[index] = true
+				results[index] = syncResult{
+					syncJob: job,
+					outcome: outcomeSkipped,
+					err:     errors.New(fmt.Sprintf("dependency %s was not synchronized", failed.pkgConfiguration.Name)),
+				}
+				complete(index)
+				completed += 1
+				continue
+			}
+
+			locks := syncJobLocks(providersMap, job)
+			if slices.ContainsFunc(locks, func(lock string) bool { return busyLocks[lock] }) {
+				continue
+			}
+
+			started[index] = true
+			jobLocks[index] = locks
+			for _, lock := range locks {
+				busyLocks[lock] = true
+			}
+			running = append(running, job.pkgConfiguration.Name)
+			progress.UpdateTitle("Synchronizing " + strings.Join(running, ", "))
+			go func() {
+				results[index] = syncPackage(ctx, job)
+				finished <- index
+			}()
+		}
+
+		if len(running) == 0 {
+			// Only reachable when every remaining job was skipped above.
+			continue
+		}
+
+		index := <-finished
+		job := syncJobs[index]
+		for _, lock := range jobLocks[index] {
+			busyLocks[lock] = false
+		}
+		running = slices.DeleteFunc(running, func(name string) bool { return name == job.pkgConfiguration.Name })
+		complete(index)
+		completed += 1
+	}
+	_, _ = progress.Stop()
+
+	return
+}
+
+// syncJobLocks returns the locks the job takes while it runs: the lock of its
+// provider and, when it is managed with sudo, the sudo one.
+func syncJobLocks(providersMap map[provider.Provider]providers.PackageProvider, job syncJob) (locks []string) {
+	packageProvider, found := providersMap[job.pkgConfiguration.Provider]
+	if !found {
+		return
+	}
+	if lock := packageProvider.LockName(); lock != "" {
+		locks = append(locks, lock)
+	}
+	if packageProvider.RunsAsRoot(job.pkgConfiguration) {
+		locks = append(locks, "sudo")
+	}
+
+	return
+}
+
+func allDone(dependencies []int, done []bool) bool {
+	for _, dependency := range dependencies {
+		if !done[dependency] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// failedDependency returns the first dependency that failed or was skipped.
+func failedDependency(dependencies []int, results []syncResult) *syncResult {
+	for _, dependency := range dependencies {
+		if results[dependency].outcome == outcomeFailed || results[dependency].outcome == outcomeSkipped {
+			return &results[dependency]
+		}
+	}
+
+	return nil
+}
+
+func syncPackage(ctx context.Context, job syncJob) (result syncResult) {
+	result = syncResult{syncJob: job}
+	pkgConfiguration := job.pkgConfiguration
+
+	if pkgConfiguration.SkipReason != "" {
+		result.outcome = outcomeSkipped
+		result.err = errors.New(pkgConfiguration.SkipReason)
+		return
+	}
+
+	unavailableProviders, _ := ctx.Value("unavailableProviders").(map[provider.Provider]bool)
+	if unavailableProviders[pkgConfiguration.Provider] {
+		result.outcome = outcomeSkipped
+		return
+	}
+	providersMap := ctx.Value("providers").(map[provider.Provider]providers.PackageProvider)
+	if packageProvider, found := providersMap[pkgConfiguration.Provider]; found {
+		if command := packageProvider.MissingCommand(pkgConfiguration); command != "" {
+			result.outcome = outcomeSkipped
+			result.err = errors.New(fmt.Sprintf("%s is not installed", command))
+			return
+		}
+	}
+
+	if pkgConfiguration.State.IsRemoved() {
+		absent, err, cmdErr := removePackage(ctx, pkgConfiguration)
+		result.err, result.cmdErr = err, cmdErr
+		if err != nil {
+			result.outcome = outcomeFailed
+		} else if absent {
+			result.outcome = outcomeAbsent
+		} else {
+			result.outcome = outcomeRemoved
+		}
+		return
+	}
+
+	satisfied, err, cmdErr := installPackage(ctx, pkgConfiguration)
+	result.err, result.cmdErr = err, cmdErr
+	if err != nil {
+		result.outcome = outcomeFailed
+	} else if satisfied {
+		result.outcome = outcomeUpToDate
+	} else {
+		result.outcome = outcomeInstalled
+	}
+
+	return
+}
+
+func printSyncResult(result syncResult) {
+	paddedProvider := formatProvider(result.pkgConfiguration)
+	name := result.pkgConfiguration.Name + pterm.Gray(" ("+result.group+")")
+
+	switch result.outcome {
+	case outcomeInstalled:
+		pterm.FgGreen.Println("| " + paddedProvider + "| Installed package " + name)
+	case outcomeRemoved:
+		pterm.FgGreen.Println("| " + paddedProvider + "| Removed package " + name)
+	case outcomeUpToDate:
+		pterm.FgGray.Println("| " + paddedProvider + "| Package " + name + " is up to date")
+	case outcomeAbsent:
+		pterm.FgGray.Println("| " + paddedProvider + "| Package " + name + " is absent")
+	case outcomeFailed:
+		printPackageError(result.err, result.cmdErr)
+	case outcomeSkipped:
+		// Packages of a missing provider were reported by checkProviders
+		// before the sync started.
+		if result.err != nil {
+			pterm.FgGray.Println("| " + paddedProvider + "| Skipped package " + name + ": " + result.err.Error())
+		}
+	}
+}
+
+// printSyncSummary prints, per group, how many packages changed, were already
+// in the requested state, failed or were skipped.
+func printSyncSummary(configuration []*models.GroupConfiguration, results []syncResult) (changed int, requested int, upToDate int) {
+	counts := make(map[string]map[packageOutcome]int)
+	for _, group := range configuration {
+		counts[group.Name] = make(map[packageOutcome]int)
+	}
+	for _, result := range results {
+		counts[result.group][result.outcome] += 1
+	}
+
+	tableData := pterm.TableData{{"Group", "Changed", "Up to date", "Failed", "Skipped"}}
+	for _, group := range configuration {
+		groupName := group.Name
+		groupCounts := counts[groupName]
+		groupChanged := groupCounts[outcomeInstalled] + groupCounts[outcomeRemoved]
+		groupUpToDate := groupCounts[outcomeUpToDate] + groupCounts[outcomeAbsent]
+		tableData = append(tableData, []string{
+			groupName,
+			pterm.Sprint(groupChanged),
+			pterm.Sprint(groupUpToDate),
+			pterm.Sprint(groupCounts[outcomeFailed]),
+			pterm.Sprint(groupCounts[outcomeSkipped]),
+		})
+		changed += groupChanged
+		upToDate += groupUpToDate
+	}
+	requested = len(results)
+
+	pterm.Println()
+	_ = pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+
+	return
+}
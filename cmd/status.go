@@ -23,30 +23,242 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"os"
+	"qrobcis/pkgsmanager/internal/models"
+	"qrobcis/pkgsmanager/internal/providers"
+	"qrobcis/pkgsmanager/internal/types/provider"
+	"slices"
+	"strings"
 
+	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 )
 
+type packageStatus string
+
+const (
+	statusInstalled packageStatus = "installed"
+	statusMissing   packageStatus = "missing"
+	statusMismatch  packageStatus = "version mismatch"
+	statusAbsent    packageStatus = "absent"
+	statusUnwanted  packageStatus = "should be absent"
+	statusNotHeld   packageStatus = "not held"
+	statusHeld      packageStatus = "should not be held"
+	statusUnknown   packageStatus = "unknown"
+	statusSkipped   packageStatus = "skipped"
+	statusError     packageStatus = "error"
+)
+
 // statusCmd represents the status command
 var statusCmd = &cobra.Command{
 	Use:   "status",
-	Short: "A brief description of your command",
+	Short: "Report packages that are missing or drifted from the configuration file",
+	Long: `Check every package declared in the configuration file against its provider
+and print, per group, which packages are installed, missing or installed at a
+different version than the requested one. Packages that should be held and
+are not are reported too, along with the held packages the configuration
+doesn't hold. The version of a .deb file that sync has not downloaded yet is
+unknown, status never downloads anything.
+The command exits with a non-zero status when anything drifted.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("status called")
+		providersMap := initProviders()
+		configuration := initConfiguration()
+		holds := heldPackages(providersMap)
+
+		drifted := 0
+		for _, group := range configuration {
+			drifted += reportGroupStatus(providersMap, holds, group)
+		}
+		reportUnmanagedHolds(configuration, holds)
+
+		if drifted > 0 {
+			pterm.Error.Printfln("%d package(s) drifted from the configuration.", drifted)
+			os.Exit(1)
+		}
+		pterm.Success.Println("All packages match the configuration.")
 	},
 }
 
-func init() {
-	rootCmd.AddCommand(statusCmd)
+func reportGroupStatus(providersMap map[provider.Provider]providers.PackageProvider, holds map[provider.Provider]providerHolds, group *models.GroupConfiguration) (drifted int) {
+	pterm.DefaultSection.Println("Group: " + group.Name)
+
+	tableData := pterm.TableData{{"Provider", "Package", "Wanted", "Installed", "Status"}}
+	for _, pkgConfiguration := range group.Packages {
+		installedVersion, status, err := packageState(providersMap, pkgConfiguration)
+		if status == statusInstalled {
+			status = holdStatus(holds, pkgConfiguration)
+		}
+		if status != statusInstalled && status != statusAbsent && status != statusSkipped {
+			drifted += 1
+		}
+
+		statusText := formatStatus(status)
+		if err != nil {
+			statusText += " " + pterm.Gray(err.Error())
+		}
+		tableData = append(tableData, []string{
+			formatProvider(pkgConfiguration),
+			pkgConfiguration.Name,
+			pkgConfiguration.Version,
+			installedVersion,
+			statusText,
+		})
+	}
 
-	// Here you will define your flags and configuration settings.
+	_ = pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+	pterm.Println()
 
-	// Cobra supports Persistent Flags which will work for this command
-	// and all subcommands, e.g.:
-	// statusCmd.PersistentFlags().String("foo", "", "A help for foo")
+	return
+}
+
+func packageState(providersMap map[provider.Provider]providers.PackageProvider, pkgConfiguration *models.PackageConfiguration) (installedVersion string, status packageStatus, err error) {
+	if pkgConfiguration.SkipReason != "" {
+		status = statusSkipped
+		err = errors.New(pkgConfiguration.SkipReason)
+		return
+	}
+
+	packageProvider, found := providersMap[pkgConfiguration.Provider]
+	if !found {
+		status = statusError
+		err = errors.New(fmt.Sprintf("Provider not supported: %s", pkgConfiguration.Provider))
+		return
+	}
+	if command := packageProvider.MissingCommand(pkgConfiguration); command != "" {
+		status = statusError
+		err = errors.New(fmt.Sprintf("%s is not installed", command))
+		return
+	}
+
+	installed, err := packageProvider.IsInstalled(pkgConfiguration)
+	if err != nil {
+		status = statusError
+		return
+	}
+	if pkgConfiguration.State.IsRemoved() {
+		status = statusAbsent
+		if installed {
+			status = statusUnwanted
+		}
+		return
+	}
+	if !installed {
+		status = statusMissing
+		return
+	}
+
+	installedVersion, err = packageProvider.InstalledVersion(pkgConfiguration)
+	if err != nil {
+		status = statusError
+		return
+	}
+
+	satisfied, err := providers.VersionSatisfied(packageProvider, pkgConfiguration, installedVersion)
+	if errors.Is(err, providers.ErrNotDownloaded) {
+		status = statusUnknown
+	} else if err != nil {
+		status = statusError
+	} else if satisfied {
+		status = statusInstalled
+	} else {
+		status = statusMismatch
+	}
+
+	return
+}
 
-	// Cobra supports local flags which will only run when this command
-	// is called directly, e.g.:
-	// statusCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+// providerHolds are the packages held through a provider and, among them or
+// not anymore, the ones pkgsmanager held itself.
+type providerHolds struct {
+	held    []string
+	managed []string
+}
+
+// heldPackages returns the packages held through each provider able to hold
+// them.
+func heldPackages(providersMap map[provider.Provider]providers.PackageProvider) (holds map[provider.Provider]providerHolds) {
+	holds = make(map[provider.Provider]providerHolds)
+	for providerName, packageProvider := range providersMap {
+		holder, ok := packageProvider.(providers.Holder)
+		if !ok || !packageProvider.IsAvailable() {
+			continue
+		}
+		held, err := holder.ListHeld()
+		if err != nil {
+			continue
+		}
+		managed, err := holder.ListManagedHolds()
+		if err != nil {
+			continue
+		}
+		holds[providerName] = providerHolds{held: held, managed: managed}
+	}
+
+	return
+}
+
+// holdStatus compares the hold of an installed package with its
+// configuration.
+func holdStatus(holds map[provider.Provider]providerHolds, pkgConfiguration *models.PackageConfiguration) packageStatus {
+	providerHolds, found := holds[pkgConfiguration.Provider]
+	hold, managed := providers.ShouldHold(pkgConfiguration, providerHolds.managed)
+	if !found || !managed {
+		return statusInstalled
+	}
+
+	isHeld := slices.Contains(providerHolds.held, pkgConfiguration.Name)
+	if hold && !isHeld {
+		return statusNotHeld
+	} else if !hold && isHeld {
+		return statusHeld
+	}
+
+	return statusInstalled
+}
+
+// reportUnmanagedHolds warns about the held packages whose hold the
+// configuration doesn't manage.
+func reportUnmanagedHolds(configuration []*models.GroupConfiguration, holds map[provider.Provider]providerHolds) {
+	for _, providerName := range slices.Sorted(maps.Keys(holds)) {
+		var unmanaged []string
+		for _, name := range holds[providerName].held {
+			managed := false
+			for _, group := range configuration {
+				if pkgConfiguration, found := group.Package(name); found && pkgConfiguration.Provider == providerName {
+					_, managed = providers.ShouldHold(pkgConfiguration, holds[providerName].managed)
+				}
+				if managed {
+					break
+				}
+			}
+			if !managed {
+				unmanaged = append(unmanaged, name)
+			}
+		}
+
+		if len(unmanaged) > 0 {
+			pterm.Warning.Printfln("Packages held with %s but not by the configuration: %s", providerName, strings.Join(unmanaged, ", "))
+			pterm.Println()
+		}
+	}
+}
+
+func formatStatus(status packageStatus) string {
+	switch status {
+	case statusInstalled, statusAbsent:
+		return pterm.Green(status)
+	case statusMismatch, statusNotHeld, statusHeld, statusUnknown:
+		return pterm.Yellow(status)
+	case statusSkipped:
+		return pterm.Gray(status)
+	default:
+		return pterm.Red(status)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
 }
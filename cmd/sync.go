@@ -28,52 +28,111 @@ import (
 	"fmt"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
+	"maps"
 	"os"
+	"qrobcis/pkgsmanager/internal/config"
 	"qrobcis/pkgsmanager/internal/models"
 	"qrobcis/pkgsmanager/internal/providers"
+	"qrobcis/pkgsmanager/internal/system"
 	"qrobcis/pkgsmanager/internal/types/provider"
+	"qrobcis/pkgsmanager/internal/types/state"
+	"slices"
+	"strings"
+)
+
+var (
+	syncDryRun bool
+	syncOutput string
+	syncJobs   int
+	syncFrozen bool
+
+	syncProfile        string
+	syncGroups         []string
+	syncExcludedGroups []string
 )
 
 // syncCmd represents the sync command
 var syncCmd = &cobra.Command{
 	Use:   "sync",
 	Short: "Install/Remove packages based on the configuration file",
+	Long: `Install and remove the packages of the configuration file. Every group is
+synchronized, unless a profile declared under the profiles key or groups are
+picked with --profile and --group; the profile named by the defaultProfile key
+is used when neither is given. The groups they depend on are synchronized too.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := context.Background()
-		pterm.Info.Println("Synchronizing packages...")
-		pterm.Println()
-
+		validateConfiguration()
 		providersMap := initProviders()
 		configuration := initConfiguration()
-		ctx = context.WithValue(ctx, "providers", providersMap)
+		selected := selectGroups(configuration)
 
-		err, cmdErr := providersMap[provider.APT].UpdateRegistry()
-		if err != nil {
-			pterm.Error.Println(err)
-			pterm.DefaultParagraph.WithMaxWidth(60).Println(cmdErr)
-			os.Exit(1)
+		if syncFrozen {
+			if err := applyLockfile(providersMap, selected); err != nil {
+				pterm.Error.Println(err)
+				os.Exit(1)
+			}
+		}
+
+		if syncDryRun {
+			if err := printPlan(planSync(providersMap, configuration, selected), syncOutput); err != nil {
+				pterm.Error.Println(err)
+				os.Exit(1)
+			}
 			return
 		}
-		totalRequestedPackages := 0
-		totalInstalledPackages := 0
 
-		for _, group := range configuration {
-			intalled, requested := installGroup(ctx, group)
-			totalInstalledPackages += intalled
-			totalRequestedPackages += requested
+		if len(selected) < len(configuration) {
+			pterm.Info.Printfln("Synchronizing groups: %s", strings.Join(groupNames(selected), ", "))
+		} else {
+			pterm.Info.Println("Synchronizing packages...")
 		}
+		pterm.Println()
 
-		err, cmdErr = providersMap[provider.APT].CleanRegistry()
-		if err != nil {
-			pterm.Error.Println(err)
-			pterm.DefaultParagraph.WithMaxWidth(60).Println(cmdErr)
+		usedProviders, unavailableProviders := checkProviders(providersMap, selected)
+		ctx = context.WithValue(ctx, "providers", providersMap)
+		ctx = context.WithValue(ctx, "unavailableProviders", unavailableProviders)
+
+		if err, cmdErr := syncRepositories(providersMap, configuration, selected); err != nil {
+			printPackageError(err, cmdErr)
 			os.Exit(1)
-			return
+		}
+		if err, cmdErr := syncPreferences(providersMap, configuration, selected); err != nil {
+			printPackageError(err, cmdErr)
+			os.Exit(1)
+		}
+		for _, usedProvider := range usedProviders {
+			err, cmdErr := providersMap[usedProvider].UpdateRegistry()
+			if err != nil {
+				pterm.Error.Println(err)
+				pterm.DefaultParagraph.WithMaxWidth(60).Println(cmdErr)
+				os.Exit(1)
+				return
+			}
+		}
+		results := runSyncJobs(ctx, newSyncJobs(selected), syncJobs)
+
+		for _, usedProvider := range usedProviders {
+			err, cmdErr := providersMap[usedProvider].CleanRegistry()
+			if err != nil {
+				pterm.Error.Println(err)
+				pterm.DefaultParagraph.WithMaxWidth(60).Println(cmdErr)
+				os.Exit(1)
+				return
+			}
 		}
 
+		changed, requested, upToDate := printSyncSummary(selected, results)
 		pterm.Println()
-		pterm.Info.Println("Installed ", totalInstalledPackages, "/", totalRequestedPackages, " packages.")
+		pterm.Info.Printfln("Synchronized %d/%d packages, %d already up to date.", changed, requested, upToDate)
+
+		// A frozen sync installs the lockfile versions, it has nothing to record.
+		// The groups left out of the sync are marked as skipped and keep their
+		// entries.
+		if !syncFrozen {
+			if err := writeLockfile(providersMap, configuration); err != nil {
+				pterm.Warning.Printfln("Failed to write the lockfile: %s", err)
+			}
+		}
 	},
 }
 
@@ -84,79 +143,274 @@ func initProviders() (providersMap map[provider.Provider]providers.PackageProvid
 	providersMap[provider.Gem] = providers.NewGemProvider()
 	providersMap[provider.Golang] = providers.NewGoProvider()
 	providersMap[provider.Snap] = providers.NewSnapProvider()
+	providersMap[provider.Pip] = providers.NewPipProvider()
+	providersMap[provider.Cargo] = providers.NewCargoProvider()
+	providersMap[provider.Flatpak] = providers.NewFlatpakProvider()
+	providersMap[provider.DNF] = providers.NewDnfProvider()
+	providersMap[provider.Pacman] = providers.NewPacmanProvider()
+	providersMap[provider.Zypper] = providers.NewZypperProvider()
+	providersMap[provider.APK] = providers.NewApkProvider()
+	providersMap[provider.Deb] = providers.NewDebProvider()
+
+	executor := providers.NewExecExecutor()
+	executor.Timeout = commandTimeout
+	for _, packageProvider := range providersMap {
+		packageProvider.SetExecutor(executor)
+	}
 
 	return
 }
 
-func initConfiguration() (configuration map[string]*models.GroupConfiguration) {
-	groups := viper.AllKeys()
-	configuration = make(map[string]*models.GroupConfiguration)
+// nativeProvider returns the package manager of the running distribution, read
+// from os-release, or provider.Unknown when it can't be told.
+func nativeProvider() provider.Provider {
+	release, err := system.ReadOSRelease()
+	if err != nil {
+		return provider.Unknown
+	}
+
+	return release.NativeProvider()
+}
+
+// usedProviders returns the providers referenced by at least one package of
+// the configuration, in a stable order.
+func usedProviders(providersMap map[provider.Provider]providers.PackageProvider, configuration []*models.GroupConfiguration) (used []provider.Provider) {
+	for providerName := range providersMap {
+		for _, group := range configuration {
+			if group.HasProvider(providerName) {
+				used = append(used, providerName)
+				break
+			}
+		}
+	}
+	slices.Sort(used)
+
+	return
+}
+
+// checkProviders returns the providers used by the configuration whose command
+// is installed, and reports up front the ones that are missing along with the
+// packages that will be skipped because of them.
+func checkProviders(providersMap map[provider.Provider]providers.PackageProvider, configuration []*models.GroupConfiguration) (available []provider.Provider, unavailable map[provider.Provider]bool) {
+	unavailable = make(map[provider.Provider]bool)
+
+	for _, usedProvider := range usedProviders(providersMap, configuration) {
+		if providersMap[usedProvider].IsAvailable() {
+			available = append(available, usedProvider)
+			continue
+		}
+
+		unavailable[usedProvider] = true
+		var skippedPackages []string
+		for _, group := range configuration {
+			for _, pkgConfiguration := range group.Packages {
+				if pkgConfiguration.Provider == usedProvider && pkgConfiguration.SkipReason == "" {
+					skippedPackages = append(skippedPackages, pkgConfiguration.Name)
+				}
+			}
+		}
+		pterm.Warning.Printfln("Provider %s is not installed, skipping: %s", usedProvider, strings.Join(skippedPackages, ", "))
+	}
+	if len(unavailable) > 0 {
+		pterm.Println()
+	}
+
+	return
+}
+
+// initConfiguration loads and merges the groups of the configuration files,
+// ordered so that groups and packages come after their dependencies. Packages
+// whose conditions don't match the machine are marked as skipped.
+func initConfiguration() (configuration []*models.GroupConfiguration) {
+	groups, err := config.LoadAll(configFiles)
+	if err == nil {
+		configuration, err = config.Order(groups)
+	}
+	if err != nil {
+		pterm.Error.Println(err)
+		os.Exit(1)
+	}
+
+	// The system alias lets one configuration work across distributions.
+	systemProvider := nativeProvider()
+	for _, group := range configuration {
+		for _, pkgConfiguration := range group.Packages {
+			if pkgConfiguration.Provider == provider.System && systemProvider != provider.Unknown {
+				pkgConfiguration.Provider = systemProvider
+			}
+		}
+	}
+	config.ApplyConditions(configuration, system.CurrentFacts())
 
-	for _, groupName := range groups {
-		groupConfiguration := models.NewGroupConfiguration(groupName)
-		configuration[groupName] = groupConfiguration
+	return
+}
 
-		var packagesConfigurations []models.RawPackageConfiguration
-		if err := viper.UnmarshalKey(groupName, &packagesConfigurations); err != nil {
-			panic(err)
+// syncRepositories writes the repositories of the packages of selected and
+// deletes the ones no package of the configuration refers to anymore, for the
+// providers managing repositories.
+func syncRepositories(providersMap map[provider.Provider]providers.PackageProvider, configuration []*models.GroupConfiguration, selected []*models.GroupConfiguration) (err error, cmdErr error) {
+	declared, err := config.LoadRepositories(configFiles)
+	if err != nil {
+		return
+	}
+	_, referenced, err := config.ResolveRepositories(declared, configuration)
+	if err != nil {
+		return
+	}
+	used, _, err := config.ResolveRepositories(declared, selected)
+	if err != nil {
+		return
+	}
+
+	for _, providerName := range slices.Sorted(maps.Keys(providersMap)) {
+		repositoryManager, ok := providersMap[providerName].(providers.RepositoryManager)
+		if !ok || !providersMap[providerName].IsAvailable() {
+			continue
 		}
-		for _, pkgConfiguration := range packagesConfigurations {
-			groupConfiguration.AddPackage(models.NewPackageConfiguration(
-				pkgConfiguration.Name,
-				pkgConfiguration.GPGKey,
-				pkgConfiguration.SourceList,
-				pkgConfiguration.Provider,
-				pkgConfiguration.Version,
-			))
+		if err, cmdErr = repositoryManager.SyncRepositories(used, referenced); err != nil {
+			return
 		}
 	}
 
 	return
 }
 
-func installGroup(ctx context.Context, group *models.GroupConfiguration) (success int, requested int) {
-	success = 0
-	requested = len(group.Packages)
+// syncPreferences pins the apt packages of selected asking for it and deletes
+// the pins of the packages of the configuration that no longer do, for the
+// providers pinning packages.
+func syncPreferences(providersMap map[provider.Provider]providers.PackageProvider, configuration []*models.GroupConfiguration, selected []*models.GroupConfiguration) (err error, cmdErr error) {
+	isPinned := func(pkgConfiguration *models.PackageConfiguration) bool {
+		return pkgConfiguration.Provider == provider.APT && !pkgConfiguration.State.IsRemoved() &&
+			(pkgConfiguration.Apt.Pin != "" || pkgConfiguration.Apt.Priority != 0)
+	}
 
-	pterm.DefaultSection.Println("Installing group: " + group.Name)
-	progress, _ := pterm.DefaultProgressbar.WithRemoveWhenDone(true).WithTotal(len(group.Packages)).WithTitle(fmt.Sprint("Installing packages for group:", pterm.Blue(" ", group.Name))).Start()
+	var referenced []string
+	for _, group := range configuration {
+		for _, pkgConfiguration := range group.Packages {
+			if isPinned(pkgConfiguration) {
+				referenced = append(referenced, pkgConfiguration.Name)
+			}
+		}
+	}
+	var pinned []*models.PackageConfiguration
+	for _, group := range selected {
+		for _, pkgConfiguration := range group.Packages {
+			if isPinned(pkgConfiguration) && pkgConfiguration.SkipReason == "" {
+				pinned = append(pinned, pkgConfiguration)
+			}
+		}
+	}
+
+	for _, providerName := range slices.Sorted(maps.Keys(providersMap)) {
+		preferencesManager, ok := providersMap[providerName].(providers.PreferencesManager)
+		if !ok || !providersMap[providerName].IsAvailable() {
+			continue
+		}
+		if err, cmdErr = preferencesManager.SyncPreferences(pinned, referenced); err != nil {
+			return
+		}
+	}
 
-	for _, packageConfiguration := range group.Packages {
-		err, cmdErr := installPackage(ctx, packageConfiguration, progress)
-		if err != nil {
-			pterm.Error.Println(err)
-			if cmdErr != nil {
-				pterm.DefaultParagraph.Printfln(cmdErr.Error())
+	return
+}
+
+// selectGroups returns the groups picked with --profile, --group and
+// --exclude-group. The packages of the other groups are marked as skipped.
+func selectGroups(configuration []*models.GroupConfiguration) (selected []*models.GroupConfiguration) {
+	profiles, err := config.LoadProfiles(configFiles)
+	if err == nil {
+		selected, err = config.Select(configuration, profiles, syncProfile, syncGroups, syncExcludedGroups)
+	}
+	if err != nil {
+		pterm.Error.Println(err)
+		os.Exit(1)
+	}
+
+	for _, group := range configuration {
+		if slices.Contains(selected, group) {
+			continue
+		}
+		for _, pkgConfiguration := range group.Packages {
+			if pkgConfiguration.SkipReason == "" {
+				pkgConfiguration.SkipReason = "group " + group.Name + " is not selected"
 			}
-		} else {
-			paddedProvider := formatProvider(packageConfiguration)
-			pterm.FgGreen.Println("| " + paddedProvider + "| Installed package " + packageConfiguration.Name)
-			success += 1
 		}
 	}
-	pterm.Println()
-	pterm.Info.Println("Successfully installed ", success, "/", len(group.Packages), " packages.")
-	pterm.Println()
 
 	return
 }
 
-func installPackage(ctx context.Context, pkgConfiguration *models.PackageConfiguration, progress *pterm.ProgressbarPrinter) (err error, cmdErr error) {
+func groupNames(configuration []*models.GroupConfiguration) (names []string) {
+	for _, group := range configuration {
+		names = append(names, group.Name)
+	}
+
+	return
+}
+
+// installPackage installs the package unless its provider reports it is
+// already installed at the requested version, in which case satisfied is true.
+// The package is then held or unheld as its configuration asks.
+func installPackage(ctx context.Context, pkgConfiguration *models.PackageConfiguration) (satisfied bool, err error, cmdErr error) {
+	packageProvider, err := packageProviderFor(ctx, pkgConfiguration)
+	if err != nil {
+		return
+	}
+
+	// A failed query is not fatal: the install below reports the real error.
+	if satisfied, _ = providers.IsSatisfied(packageProvider, pkgConfiguration); !satisfied {
+		if err, cmdErr = packageProvider.InstallPackage(pkgConfiguration); err != nil {
+			return
+		}
+	}
+	_, err, cmdErr = providers.SyncHold(packageProvider, pkgConfiguration)
+
+	return
+}
+
+// removePackage uninstalls the package unless its provider reports it is
+// not installed, in which case absent is true.
+func removePackage(ctx context.Context, pkgConfiguration *models.PackageConfiguration) (absent bool, err error, cmdErr error) {
+	packageProvider, err := packageProviderFor(ctx, pkgConfiguration)
+	if err != nil {
+		return
+	}
+
+	installed, err := packageProvider.IsInstalled(pkgConfiguration)
+	if err != nil {
+		return
+	}
+	if absent = !installed; absent {
+		return
+	}
+	err, cmdErr = packageProvider.RemovePackage(pkgConfiguration)
+
+	return
+}
 
-	progress.UpdateTitle("Installing package " + pkgConfiguration.Name)
+func packageProviderFor(ctx context.Context, pkgConfiguration *models.PackageConfiguration) (packageProvider providers.PackageProvider, err error) {
+	if pkgConfiguration.State == state.Unknown {
+		err = errors.New(fmt.Sprintf("State not supported for package %s", pkgConfiguration.Name))
+		return
+	}
 
 	var providersMap map[provider.Provider]providers.PackageProvider
 	providersMap = ctx.Value("providers").(map[provider.Provider]providers.PackageProvider)
-	if pkgConfiguration.Provider != provider.Unknown {
-		err, cmdErr = providersMap[pkgConfiguration.Provider].InstallPackage(pkgConfiguration)
-	} else {
+	packageProvider, found := providersMap[pkgConfiguration.Provider]
+	if !found {
 		err = errors.New(fmt.Sprintf("Provider not supported: %s", pkgConfiguration.Provider))
 	}
-	progress.Increment()
 
 	return
 }
 
+func printPackageError(err error, cmdErr error) {
+	pterm.Error.Println(err)
+	if cmdErr != nil {
+		pterm.DefaultParagraph.Println(cmdErr.Error())
+	}
+}
+
 func formatProvider(pkgConfiguration *models.PackageConfiguration) (paddedProvider string) {
 	var providerStyle *pterm.Style
 	if pkgConfiguration.Provider == provider.APT || pkgConfiguration.Provider == provider.Unset {
@@ -172,14 +426,36 @@ func formatProvider(pkgConfiguration *models.PackageConfiguration) (paddedProvid
 		providerStyle = pterm.NewStyle(pterm.Bold, pterm.FgCyan)
 	} else if pkgConfiguration.Provider == provider.Snap {
 		providerStyle = pterm.NewStyle(pterm.Bold, pterm.FgMagenta)
+	} else if pkgConfiguration.Provider == provider.Cargo {
+		providerStyle = pterm.NewStyle(pterm.Bold, pterm.FgLightRed)
+	} else if pkgConfiguration.Provider == provider.Flatpak {
+		providerStyle = pterm.NewStyle(pterm.Bold, pterm.FgLightBlue)
+	} else if pkgConfiguration.Provider == provider.DNF {
+		providerStyle = pterm.NewStyle(pterm.Bold, pterm.FgLightMagenta)
+	} else if pkgConfiguration.Provider == provider.Pacman {
+		providerStyle = pterm.NewStyle(pterm.Bold, pterm.FgLightGreen)
+	} else if pkgConfiguration.Provider == provider.Zypper {
+		providerStyle = pterm.NewStyle(pterm.Bold, pterm.FgLightYellow)
+	} else if pkgConfiguration.Provider == provider.APK {
+		providerStyle = pterm.NewStyle(pterm.Bold, pterm.FgLightCyan)
+	} else if pkgConfiguration.Provider == provider.Deb {
+		providerStyle = pterm.NewStyle(pterm.Bold, pterm.FgLightWhite)
 	} else {
 		providerStyle = pterm.NewStyle(pterm.Bold, pterm.FgDefault)
 	}
-	paddedProvider = providerStyle.Sprintf("%-5s", pkgConfiguration.Provider)
+	paddedProvider = providerStyle.Sprintf("%-7s", pkgConfiguration.Provider)
 
 	return
 }
 
 func init() {
 	rootCmd.AddCommand(syncCmd)
+
+	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Print the commands sync would run without executing anything")
+	syncCmd.Flags().StringVarP(&syncOutput, "output", "o", "text", "Dry-run output format: text or json")
+	syncCmd.Flags().IntVarP(&syncJobs, "jobs", "j", 4, "Maximum number of packages synchronized at the same time")
+	syncCmd.Flags().BoolVar(&syncFrozen, "frozen", false, "Install the versions recorded in the lockfile")
+	syncCmd.Flags().StringVar(&syncProfile, "profile", "", "Profile of the configuration to synchronize")
+	syncCmd.Flags().StringSliceVarP(&syncGroups, "group", "g", nil, "Group to synchronize, can be repeated")
+	syncCmd.Flags().StringSliceVar(&syncExcludedGroups, "exclude-group", nil, "Group to leave out, can be repeated")
 }
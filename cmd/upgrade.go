@@ -0,0 +1,266 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"qrobcis/pkgsmanager/internal/models"
+	"qrobcis/pkgsmanager/internal/providers"
+	"qrobcis/pkgsmanager/internal/types/provider"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+type upgradeStatus string
+
+const (
+	upgradeUpgraded     upgradeStatus = "upgraded"
+	upgradeUpToDate     upgradeStatus = "up to date"
+	upgradePinned       upgradeStatus = "pinned"
+	upgradeNotInstalled upgradeStatus = "not installed"
+	upgradeSkipped      upgradeStatus = "skipped"
+	upgradeFailed       upgradeStatus = "error"
+)
+
+type upgradeResult struct {
+	group            string
+	pkgConfiguration *models.PackageConfiguration
+	before           string
+	after            string
+	status           upgradeStatus
+}
+
+var upgradeSystem bool
+
+// upgradeCmd represents the upgrade command
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade [group|package]",
+	Short: "Upgrade the packages of the configuration file to their latest version",
+	Long: `Upgrade the packages declared in the configuration file, all of them or
+only the ones of a group or a single package. Packages pinned to a version or
+held in the configuration are left as they are, as are the ones that are not
+installed or meant to be absent: sync takes care of those.
+The versions before and after the upgrade are printed, and the lockfile is
+refreshed when there is one.
+Only the managed packages are upgraded: --system first upgrades every package
+of the system, with the providers able to do so such as pacman.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		providersMap := initProviders()
+		configuration := initConfiguration()
+
+		target := ""
+		if len(args) > 0 {
+			target = args[0]
+		}
+		jobs, err := upgradeTargets(configuration, target)
+		if err != nil {
+			pterm.Error.Println(err)
+			os.Exit(1)
+		}
+
+		usedProviders, _ := checkProviders(providersMap, upgradableConfiguration(providersMap, jobs))
+		for _, usedProvider := range usedProviders {
+			err, cmdErr := providersMap[usedProvider].UpdateRegistry()
+			if err != nil {
+				printPackageError(err, cmdErr)
+				os.Exit(1)
+			}
+		}
+		if upgradeSystem {
+			for _, usedProvider := range usedProviders {
+				systemUpgrader, ok := providersMap[usedProvider].(providers.SystemUpgrader)
+				if !ok {
+					continue
+				}
+				if err, cmdErr := systemUpgrader.UpgradeSystem(); err != nil {
+					printPackageError(err, cmdErr)
+					os.Exit(1)
+				}
+			}
+		}
+
+		var results []upgradeResult
+		failed := 0
+		for _, job := range jobs {
+			result := upgradePackage(providersMap, job)
+			if result.status == upgradeFailed {
+				failed += 1
+			}
+			results = append(results, result)
+		}
+		printUpgradeResults(results)
+
+		if path, err := lockfilePath(); err == nil {
+			if _, err = os.Stat(path); err == nil {
+				if err = writeLockfile(providersMap, configuration); err != nil {
+					pterm.Warning.Printfln("Failed to write the lockfile: %s", err)
+				}
+			}
+		}
+
+		if failed > 0 {
+			pterm.Error.Printfln("%d package(s) failed to upgrade.", failed)
+			os.Exit(1)
+		}
+	},
+}
+
+// upgradeTargets returns the packages to upgrade: every package without a
+// target, the packages of the group named target, or else the packages named
+// target.
+func upgradeTargets(configuration []*models.GroupConfiguration, target string) (jobs []syncJob, err error) {
+	for _, group := range configuration {
+		groupTargeted := target == "" || strings.EqualFold(group.Name, target)
+		for _, pkgConfiguration := range group.Packages {
+			if groupTargeted || pkgConfiguration.Name == target {
+				jobs = append(jobs, syncJob{group: group.Name, pkgConfiguration: pkgConfiguration})
+			}
+		}
+	}
+
+	if len(jobs) == 0 && target != "" {
+		err = errors.New(fmt.Sprintf("No group or package named %s in the configuration", target))
+	}
+
+	return
+}
+
+// upgradableConfiguration groups the packages of jobs that may be upgraded back
+// into a configuration, leaving out the pinned and absent ones.
+func upgradableConfiguration(providersMap map[provider.Provider]providers.PackageProvider, jobs []syncJob) (configuration []*models.GroupConfiguration) {
+	groups := make(map[string]*models.GroupConfiguration)
+	for _, job := range jobs {
+		packageProvider, found := providersMap[job.pkgConfiguration.Provider]
+		if (found && isPinned(packageProvider, job.pkgConfiguration)) || job.pkgConfiguration.State.IsRemoved() {
+			continue
+		}
+
+		group, found := groups[job.group]
+		if !found {
+			group = models.NewGroupConfiguration(job.group)
+			groups[job.group] = group
+			configuration = append(configuration, group)
+		}
+		group.AddPackage(job.pkgConfiguration)
+	}
+
+	return
+}
+
+func upgradePackage(providersMap map[provider.Provider]providers.PackageProvider, job syncJob) (result upgradeResult) {
+	pkgConfiguration := job.pkgConfiguration
+	result = upgradeResult{group: job.group, pkgConfiguration: pkgConfiguration}
+
+	packageProvider, found := providersMap[pkgConfiguration.Provider]
+	if found && isPinned(packageProvider, pkgConfiguration) {
+		result.status = upgradePinned
+		return
+	}
+	if pkgConfiguration.State.IsRemoved() || pkgConfiguration.SkipReason != "" {
+		result.status = upgradeSkipped
+		return
+	}
+	if !found || !packageProvider.IsAvailable() || packageProvider.MissingCommand(pkgConfiguration) != "" {
+		result.status = upgradeSkipped
+		return
+	}
+
+	installed, err := packageProvider.IsInstalled(pkgConfiguration)
+	if err != nil {
+		result.status = upgradeFailed
+		printPackageError(err, nil)
+		return
+	}
+	if !installed {
+		result.status = upgradeNotInstalled
+		return
+	}
+
+	result.before, _ = packageProvider.InstalledVersion(pkgConfiguration)
+	spinner, _ := pterm.DefaultSpinner.WithRemoveWhenDone(true).Start("Upgrading " + pkgConfiguration.Name)
+	err, cmdErr := packageProvider.UpgradePackage(pkgConfiguration)
+	_ = spinner.Stop()
+	if err != nil {
+		result.status = upgradeFailed
+		printPackageError(err, cmdErr)
+		return
+	}
+
+	result.after, _ = packageProvider.InstalledVersion(pkgConfiguration)
+	if result.after == result.before {
+		result.status = upgradeUpToDate
+	} else {
+		result.status = upgradeUpgraded
+	}
+
+	return
+}
+
+// isPinned tells whether the package is pinned to a version, as opposed to a
+// channel or a branch it follows, or held by a provider holding packages.
+func isPinned(packageProvider providers.PackageProvider, pkgConfiguration *models.PackageConfiguration) bool {
+	if _, ok := packageProvider.(providers.Holder); ok {
+		if hold, _ := providers.ShouldHold(pkgConfiguration, nil); hold {
+			return true
+		}
+	}
+
+	return packageProvider.PinsVersion(pkgConfiguration)
+}
+
+func printUpgradeResults(results []upgradeResult) {
+	tableData := pterm.TableData{{"Provider", "Package", "Group", "Before", "After", "Status"}}
+	for _, result := range results {
+		status := string(result.status)
+		if result.status == upgradeUpgraded {
+			status = pterm.Green(status)
+		} else if result.status == upgradeFailed {
+			status = pterm.Red(status)
+		} else {
+			status = pterm.Gray(status)
+		}
+
+		tableData = append(tableData, []string{
+			formatProvider(result.pkgConfiguration),
+			result.pkgConfiguration.Name,
+			result.group,
+			result.before,
+			result.after,
+			status,
+		})
+	}
+
+	pterm.Println()
+	_ = pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+}
+
+func init() {
+	rootCmd.AddCommand(upgradeCmd)
+
+	upgradeCmd.Flags().BoolVar(&upgradeSystem, "system", false, "Upgrade every package of the system first, with the providers supporting it")
+}
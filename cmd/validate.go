@@ -0,0 +1,79 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"os"
+	"qrobcis/pkgsmanager/internal/config"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// validateCmd represents the config validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration files against the configuration schema",
+	Long: `Check every configuration file against the JSON Schema printed by
+pkgsmanager config schema, and look for packages declared twice in a group,
+GPG keys given without a source list, or dependencies on unknown packages and
+groups or forming a cycle. Each problem is reported with its file and line
+number, and the command exits with a non-zero status when there is any.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		validateConfiguration()
+		pterm.Success.Printfln("%d configuration file(s) are valid.", len(configFiles))
+	},
+}
+
+// schemaCmd represents the config schema command
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the configuration file",
+	Run: func(cmd *cobra.Command, args []string) {
+		_, _ = os.Stdout.Write(config.Schema)
+	},
+}
+
+// validateConfiguration reports the problems of the configuration files and
+// exits when there is any.
+func validateConfiguration() {
+	problems, err := config.ValidateAll(configFiles)
+	if err != nil {
+		pterm.Error.Println(err)
+		os.Exit(1)
+	}
+	if len(problems) == 0 {
+		return
+	}
+
+	for _, problem := range problems {
+		pterm.Error.Println(problem)
+	}
+	pterm.Error.Printfln("%d problem(s) found in the configuration.", len(problems))
+	os.Exit(1)
+}
+
+func init() {
+	configCmd.AddCommand(validateCmd)
+	configCmd.AddCommand(schemaCmd)
+}
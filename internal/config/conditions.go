@@ -0,0 +1,102 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package config
+
+import (
+	"fmt"
+	"path"
+	"qrobcis/pkgsmanager/internal/models"
+	"qrobcis/pkgsmanager/internal/system"
+	"slices"
+	"strings"
+)
+
+// ApplyConditions marks the packages whose conditions, or the ones of their
+// group, don't match the machine as skipped.
+func ApplyConditions(groups []*models.GroupConfiguration, facts system.Facts) {
+	for _, group := range groups {
+		groupReason := Evaluate(group.When, facts)
+		for _, pkgConfiguration := range group.Packages {
+			if groupReason != "" {
+				pkgConfiguration.SkipReason = "group " + group.Name + ": " + groupReason
+			} else {
+				pkgConfiguration.SkipReason = Evaluate(pkgConfiguration.When, facts)
+			}
+		}
+	}
+}
+
+// Evaluate checks a condition against the facts of the machine and returns
+// why it doesn't match, or an empty string when it does.
+func Evaluate(condition *models.Condition, facts system.Facts) (reason string) {
+	if condition == nil {
+		return
+	}
+
+	if len(condition.Hostname) > 0 && !matchesAny(condition.Hostname, facts.Hostname) {
+		return fmt.Sprintf("hostname %s doesn't match %s", facts.Hostname, strings.Join(condition.Hostname, ", "))
+	}
+	if len(condition.OS) > 0 && !slices.ContainsFunc(condition.OS, facts.Release.Is) {
+		return fmt.Sprintf("OS %s is not %s", facts.Release.ID, strings.Join(condition.OS, ", "))
+	}
+	if len(condition.OSVersion) > 0 && !matchesAny(condition.OSVersion, facts.Release.VersionID) {
+		return fmt.Sprintf("OS version %s doesn't match %s", facts.Release.VersionID, strings.Join(condition.OSVersion, ", "))
+	}
+
+	architecture := system.NormalizeArchitecture(facts.Architecture)
+	if len(condition.Arch) > 0 && !slices.ContainsFunc(condition.Arch, func(wanted string) bool {
+		return system.NormalizeArchitecture(wanted) == architecture
+	}) {
+		return fmt.Sprintf("architecture %s is not %s", facts.Architecture, strings.Join(condition.Arch, ", "))
+	}
+
+	variables := make([]string, 0, len(condition.Env))
+	for variable := range condition.Env {
+		variables = append(variables, variable)
+	}
+	slices.Sort(variables)
+	for _, variable := range variables {
+		value, set := facts.LookupEnv(variable)
+		if !set {
+			return fmt.Sprintf("environment variable %s is not set", variable)
+		}
+		if matched, _ := path.Match(condition.Env[variable], value); !matched {
+			return fmt.Sprintf("environment variable %s doesn't match %s", variable, condition.Env[variable])
+		}
+	}
+
+	for _, command := range condition.Command {
+		if !facts.HasCommand(command) {
+			return fmt.Sprintf("command %s not found", command)
+		}
+	}
+
+	return
+}
+
+func matchesAny(patterns []string, value string) bool {
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		matched, _ := path.Match(pattern, value)
+		return matched
+	})
+}
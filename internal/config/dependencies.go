@@ -0,0 +1,165 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"qrobcis/pkgsmanager/internal/models"
+	"strings"
+)
+
+// Order sorts the groups, and the packages of each group, so that everything
+// comes after what it depends on. Items without dependencies between them keep
+// the order of the configuration file. A group depends on other groups and a
+// package on other packages of the same group.
+func Order(groups []*models.GroupConfiguration) (ordered []*models.GroupConfiguration, err error) {
+	groupNames := make([]string, 0, len(groups))
+	groupDependencies := make(map[string][]string)
+	groupsByName := make(map[string]*models.GroupConfiguration)
+	for _, group := range groups {
+		groupNames = append(groupNames, group.Name)
+		groupDependencies[group.Name] = group.DependsOn
+		groupsByName[group.Name] = group
+	}
+
+	orderedNames, err := topologicalOrder("group", groupNames, groupDependencies)
+	if err != nil {
+		return
+	}
+
+	for _, groupName := range orderedNames {
+		group := groupsByName[groupName]
+		packageNames := make([]string, 0, len(group.Packages))
+		packageDependencies := make(map[string][]string)
+		for _, pkgConfiguration := range group.Packages {
+			packageNames = append(packageNames, pkgConfiguration.Name)
+			packageDependencies[pkgConfiguration.Name] = pkgConfiguration.DependsOn
+		}
+
+		var orderedPackages []string
+		orderedPackages, err = topologicalOrder("package", packageNames, packageDependencies)
+		if err != nil {
+			err = errors.New(fmt.Sprintf("group %s: %s", groupName, err))
+			return
+		}
+
+		orderedGroup := models.NewGroupConfiguration(group.Name)
+		orderedGroup.Origin = group.Origin
+		orderedGroup.DependsOn = group.DependsOn
+		orderedGroup.When = group.When
+		for _, packageName := range orderedPackages {
+			pkgConfiguration, _ := group.Package(packageName)
+			orderedGroup.AddPackage(pkgConfiguration)
+		}
+		ordered = append(ordered, orderedGroup)
+	}
+
+	return
+}
+
+// cycleError reports names depending on each other, the first name of the
+// cycle repeated last.
+type cycleError struct {
+	kind  string
+	cycle []string
+}
+
+func (err *cycleError) Error() string {
+	return fmt.Sprintf("%s dependency cycle: %s", err.kind, strings.Join(err.cycle, " -> "))
+}
+
+// topologicalOrder places each name after its dependencies, always picking the
+// first name of the original order that is ready, so the result is stable.
+func topologicalOrder(kind string, names []string, dependencies map[string][]string) (ordered []string, err error) {
+	for _, name := range names {
+		for _, dependency := range dependencies[name] {
+			if _, found := dependencies[dependency]; !found {
+				err = errors.New(fmt.Sprintf("%s %s depends on unknown %s %s", kind, name, kind, dependency))
+				return
+			}
+		}
+	}
+
+	placed := make(map[string]bool)
+	for len(ordered) < len(names) {
+		progressed := false
+		for _, name := range names {
+			if placed[name] || !allPlaced(dependencies[name], placed) {
+				continue
+			}
+			placed[name] = true
+			ordered = append(ordered, name)
+			progressed = true
+			break
+		}
+
+		if !progressed {
+			err = &cycleError{kind: kind, cycle: findCycle(names, dependencies, placed)}
+			return
+		}
+	}
+
+	return
+}
+
+func allPlaced(names []string, placed map[string]bool) bool {
+	for _, name := range names {
+		if !placed[name] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// findCycle walks the dependencies of the names left unplaced until a name
+// comes back, and returns the path of the cycle, first name repeated last.
+func findCycle(names []string, dependencies map[string][]string, placed map[string]bool) (cycle []string) {
+	var start string
+	for _, name := range names {
+		if !placed[name] {
+			start = name
+			break
+		}
+	}
+
+	position := make(map[string]int)
+	current := start
+	for {
+		if index, visited := position[current]; visited {
+			return append(cycle[index:], current)
+		}
+		position[current] = len(cycle)
+		cycle = append(cycle, current)
+
+		// Every unplaced name has at least one unplaced dependency, otherwise it
+		// would have been placed.
+		for _, dependency := range dependencies[current] {
+			if !placed[dependency] {
+				current = dependency
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package config
+
+import (
+	"qrobcis/pkgsmanager/internal/models"
+	"slices"
+	"testing"
+)
+
+func TestTopologicalOrder(t *testing.T) {
+	tests := []struct {
+		name         string
+		names        []string
+		dependencies map[string][]string
+		want         []string
+		wantErr      string
+	}{
+		{
+			name:         "no dependencies keeps the order",
+			names:        []string{"c", "a", "b"},
+			dependencies: map[string][]string{"c": nil, "a": nil, "b": nil},
+			want:         []string{"c", "a", "b"},
+		},
+		{
+			name:         "dependencies come first",
+			names:        []string{"app", "runtime", "tools"},
+			dependencies: map[string][]string{"app": {"runtime"}, "runtime": nil, "tools": nil},
+			want:         []string{"runtime", "app", "tools"},
+		},
+		{
+			name:         "chained dependencies",
+			names:        []string{"a", "b", "c"},
+			dependencies: map[string][]string{"a": {"b"}, "b": {"c"}, "c": nil},
+			want:         []string{"c", "b", "a"},
+		},
+		{
+			name:         "unknown dependency",
+			names:        []string{"a"},
+			dependencies: map[string][]string{"a": {"missing"}},
+			wantErr:      "package a depends on unknown package missing",
+		},
+		{
+			name:         "cycle",
+			names:        []string{"x", "a", "b"},
+			dependencies: map[string][]string{"x": nil, "a": {"b"}, "b": {"a"}},
+			wantErr:      "package dependency cycle: a -> b -> a",
+		},
+		{
+			name:         "self dependency",
+			names:        []string{"a"},
+			dependencies: map[string][]string{"a": {"a"}},
+			wantErr:      "package dependency cycle: a -> a",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ordered, err := topologicalOrder("package", test.names, test.dependencies)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("topologicalOrder() error = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("topologicalOrder() error = %v", err)
+			}
+			if !slices.Equal(ordered, test.want) {
+				t.Fatalf("topologicalOrder() = %q, want %q", ordered, test.want)
+			}
+		})
+	}
+}
+
+func TestOrder(t *testing.T) {
+	newGroup := func(name string, dependsOn []string, packages ...*models.PackageConfiguration) *models.GroupConfiguration {
+		group := models.NewGroupConfiguration(name)
+		group.DependsOn = dependsOn
+		for _, pkgConfiguration := range packages {
+			group.AddPackage(pkgConfiguration)
+		}
+		return group
+	}
+
+	groups := []*models.GroupConfiguration{
+		newGroup("dev", []string{"base"},
+			&models.PackageConfiguration{Name: "typescript", DependsOn: []string{"nodejs"}},
+			&models.PackageConfiguration{Name: "nodejs"},
+			&models.PackageConfiguration{Name: "jq"},
+		),
+		newGroup("base", nil, &models.PackageConfiguration{Name: "curl"}),
+		newGroup("tools", nil, &models.PackageConfiguration{Name: "git"}),
+	}
+
+	ordered, err := Order(groups)
+	if err != nil {
+		t.Fatalf("Order() error = %v", err)
+	}
+	var names []string
+	for _, group := range ordered {
+		names = append(names, group.Name)
+		for _, pkgConfiguration := range group.Packages {
+			names = append(names, group.Name+"/"+pkgConfiguration.Name)
+		}
+	}
+	want := []string{"base", "base/curl", "dev", "dev/nodejs", "dev/typescript", "dev/jq", "tools", "tools/git"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("Order() = %q, want %q", names, want)
+	}
+
+	groups = append(groups, newGroup("broken", nil, &models.PackageConfiguration{Name: "a", DependsOn: []string{"b"}}))
+	if _, err = Order(groups); err == nil || err.Error() != "group broken: package a depends on unknown package b" {
+		t.Fatalf("Order() error = %v, want the unknown dependency of group broken", err)
+	}
+}
@@ -0,0 +1,289 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"qrobcis/pkgsmanager/internal/models"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Editor changes the groups and packages of a configuration file. The file is
+// edited as a YAML document so comments and the order of the other entries
+// are kept. Group names are matched case-insensitively.
+type Editor struct {
+	path     string
+	mode     os.FileMode
+	document yaml.Node
+}
+
+// OpenEditor reads the configuration file to edit. A missing file is edited as
+// an empty configuration and created on Save.
+func OpenEditor(path string) (editor *Editor, err error) {
+	editor = &Editor{path: path, mode: 0644}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		content, err = nil, nil
+	} else if err == nil {
+		var info os.FileInfo
+		if info, err = os.Stat(path); err == nil {
+			editor.mode = info.Mode()
+		}
+	}
+	if err != nil {
+		return
+	}
+
+	if err = yaml.Unmarshal(content, &editor.document); err != nil {
+		err = errors.New(fmt.Sprintf("%s: %s", path, err))
+		return
+	}
+	if len(editor.document.Content) == 0 {
+		editor.document = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if editor.root().Kind != yaml.MappingNode {
+		err = errors.New(fmt.Sprintf("%s:%d: the configuration must be a mapping of groups", path, editor.root().Line))
+	}
+
+	return
+}
+
+// Save writes the edited configuration back to its file.
+func (editor *Editor) Save() (err error) {
+	output := new(bytes.Buffer)
+	encoder := yaml.NewEncoder(output)
+	encoder.SetIndent(2)
+	if err = encoder.Encode(&editor.document); err != nil {
+		return
+	}
+
+	return os.WriteFile(editor.path, output.Bytes(), editor.mode)
+}
+
+// HasGroup tells whether the file declares the group.
+func (editor *Editor) HasGroup(groupName string) bool {
+	return editor.groupIndex(groupName) >= 0
+}
+
+// AddGroup appends an empty group to the file.
+func (editor *Editor) AddGroup(groupName string) (err error) {
+	if IsReservedKey(groupName) {
+		return errors.New(fmt.Sprintf("%s is a reserved key, it can't be used as a group name", groupName))
+	}
+	if editor.HasGroup(groupName) {
+		return errors.New(fmt.Sprintf("Group %s already exists in %s", groupName, editor.path))
+	}
+
+	root := editor.root()
+	root.Content = append(root.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: groupName},
+		&yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle},
+	)
+
+	return
+}
+
+// RemoveGroup deletes the group along with its packages.
+func (editor *Editor) RemoveGroup(groupName string) (err error) {
+	index := editor.groupIndex(groupName)
+	if index < 0 {
+		return errors.New(fmt.Sprintf("Group %s not found in %s", groupName, editor.path))
+	}
+
+	root := editor.root()
+	root.Content = append(root.Content[:index], root.Content[index+2:]...)
+
+	return
+}
+
+// AddPackage appends the package to the group, creating the group when it
+// doesn't exist yet.
+func (editor *Editor) AddPackage(groupName string, pkgConfiguration *models.PackageConfiguration) (err error) {
+	var packageNode yaml.Node
+	if err = packageNode.Encode(pkgConfiguration); err != nil {
+		return
+	}
+
+	return editor.insertPackage(groupName, pkgConfiguration.Name, &packageNode)
+}
+
+// RemovePackage deletes the package from the group, along with the dependsOn
+// entries of the other packages of the group naming it.
+func (editor *Editor) RemovePackage(groupName string, packageName string) (err error) {
+	if _, err = editor.takePackage(groupName, packageName); err != nil {
+		return
+	}
+
+	packages, err := editor.packagesNode(groupName)
+	if err != nil {
+		return
+	}
+	for _, packageNode := range packages.Content {
+		removeDependency(packageNode, packageName)
+	}
+
+	return
+}
+
+// MovePackage moves the package from a group to a group of target, which may
+// be the editor itself, creating the target group when it doesn't exist yet.
+// The package entry is moved as is, comments included.
+func (editor *Editor) MovePackage(packageName string, fromGroup string, target *Editor, toGroup string) (err error) {
+	packageNode, err := editor.takePackage(fromGroup, packageName)
+	if err != nil {
+		return
+	}
+
+	return target.insertPackage(toGroup, packageName, packageNode)
+}
+
+// Path returns the path of the edited file.
+func (editor *Editor) Path() string {
+	return editor.path
+}
+
+func (editor *Editor) root() *yaml.Node {
+	return editor.document.Content[0]
+}
+
+// groupIndex returns the index of the key of the group in the root mapping,
+// or -1. Reserved keys are never groups.
+func (editor *Editor) groupIndex(groupName string) int {
+	root := editor.root()
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if strings.EqualFold(root.Content[i].Value, groupName) && !IsReservedKey(root.Content[i].Value) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// packagesNode returns the sequence holding the packages of the group: the
+// group itself in its short form, its packages key in its long form. A group
+// without packages gets an empty sequence.
+func (editor *Editor) packagesNode(groupName string) (packages *yaml.Node, err error) {
+	index := editor.groupIndex(groupName)
+	if index < 0 {
+		return nil, errors.New(fmt.Sprintf("Group %s not found in %s", groupName, editor.path))
+	}
+
+	root := editor.root()
+	groupNode := root.Content[index+1]
+	switch {
+	case groupNode.Kind == yaml.SequenceNode:
+		packages = groupNode
+	case groupNode.Kind == yaml.MappingNode:
+		packages = mappingValue(groupNode, "packages")
+		if packages == nil {
+			packages = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+			groupNode.Content = append(groupNode.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "packages"}, packages)
+		}
+	case groupNode.Kind == yaml.ScalarNode && groupNode.ShortTag() == "!!null":
+		packages = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		root.Content[index+1] = packages
+	}
+
+	if packages == nil || (packages.Kind != yaml.SequenceNode && packages.ShortTag() != "!!null") {
+		return nil, errors.New(fmt.Sprintf("%s:%d: group %s must be a list of packages or a mapping", editor.path, groupNode.Line, groupName))
+	}
+	if packages.Kind != yaml.SequenceNode {
+		*packages = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	}
+
+	return
+}
+
+func (editor *Editor) insertPackage(groupName string, packageName string, packageNode *yaml.Node) (err error) {
+	if !editor.HasGroup(groupName) {
+		if err = editor.AddGroup(groupName); err != nil {
+			return
+		}
+	}
+
+	packages, err := editor.packagesNode(groupName)
+	if err != nil {
+		return
+	}
+	if packageIndex(packages, packageName) >= 0 {
+		return errors.New(fmt.Sprintf("Package %s is already declared in group %s", packageName, groupName))
+	}
+
+	// A flow sequence such as [] would print the new entry inline.
+	packages.Style = 0
+	packages.Content = append(packages.Content, packageNode)
+
+	return
+}
+
+func (editor *Editor) takePackage(groupName string, packageName string) (packageNode *yaml.Node, err error) {
+	packages, err := editor.packagesNode(groupName)
+	if err != nil {
+		return
+	}
+
+	index := packageIndex(packages, packageName)
+	if index < 0 {
+		return nil, errors.New(fmt.Sprintf("Package %s not found in group %s", packageName, groupName))
+	}
+	packageNode = packages.Content[index]
+	packages.Content = append(packages.Content[:index], packages.Content[index+1:]...)
+
+	return
+}
+
+func packageIndex(packages *yaml.Node, packageName string) int {
+	for i, packageNode := range packages.Content {
+		if nameNode := mappingValue(packageNode, "name"); nameNode != nil && nameNode.Value == packageName {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// removeDependency drops the dependency from the dependsOn list of the package
+// node, and the list itself once it is empty.
+func removeDependency(packageNode *yaml.Node, dependency string) {
+	for i := 0; i+1 < len(packageNode.Content); i += 2 {
+		if packageNode.Content[i].Value != "dependsOn" {
+			continue
+		}
+
+		dependsOn := packageNode.Content[i+1]
+		dependsOn.Content = slices.DeleteFunc(dependsOn.Content, func(node *yaml.Node) bool {
+			return node.Value == dependency
+		})
+		if len(dependsOn.Content) == 0 {
+			packageNode.Content = append(packageNode.Content[:i], packageNode.Content[i+2:]...)
+		}
+		return
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRemovePackageDropsDependencies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `dev:
+  - name: nodejs
+  - name: typescript
+    dependsOn: [nodejs]
+  - name: yarn
+    dependsOn:
+      - nodejs
+      - typescript
+tools:
+  - name: eslint
+    dependsOn: [nodejs]
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	editor, err := OpenEditor(path)
+	if err != nil {
+		t.Fatalf("OpenEditor() error = %v", err)
+	}
+	if err = editor.RemovePackage("dev", "nodejs"); err != nil {
+		t.Fatalf("RemovePackage() error = %v", err)
+	}
+	if err = editor.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	want := `dev:
+  - name: typescript
+  - name: yarn
+    dependsOn:
+      - typescript
+tools:
+  - name: eslint
+    dependsOn: [nodejs]
+`
+	if saved, _ := os.ReadFile(path); string(saved) != want {
+		t.Fatalf("RemovePackage() saved\n%s\nwant\n%s", saved, want)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"qrobcis/pkgsmanager/internal/models"
+	"slices"
+)
+
+// SystemDirectory holds the system-wide configuration files, shared by every
+// user of the machine.
+const SystemDirectory = "/etc/pkgsmanager"
+
+// FileName is the name of the user and project configuration files.
+const FileName = ".pkgsmanager.yaml"
+
+// Discover returns the configuration files found on the system, from the least
+// to the most specific: the YAML files of SystemDirectory in lexical order,
+// the user file in the home directory, then the project file in the working
+// directory.
+func Discover() (paths []string, err error) {
+	systemPaths, err := filepath.Glob(filepath.Join(SystemDirectory, "*.yaml"))
+	if err != nil {
+		return
+	}
+	slices.Sort(systemPaths)
+	paths = append(paths, systemPaths...)
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return
+	}
+	workingDirectory, err := os.Getwd()
+	if err != nil {
+		return
+	}
+
+	for _, directory := range []string{home, workingDirectory} {
+		path := filepath.Join(directory, FileName)
+		if slices.Contains(paths, path) {
+			continue
+		}
+		if _, statErr := os.Stat(path); statErr == nil {
+			paths = append(paths, path)
+		} else if !errors.Is(statErr, os.ErrNotExist) {
+			return paths, statErr
+		}
+	}
+
+	return
+}
+
+// LoadAll loads the configuration files in order and merges their groups. A
+// group declared again in a later file replaces the earlier one, keeping its
+// position; new groups are added after the ones already loaded.
+func LoadAll(paths []string) (groups []*models.GroupConfiguration, err error) {
+	for _, path := range paths {
+		var fileGroups []*models.GroupConfiguration
+		fileGroups, err = Load(path)
+		if err != nil {
+			return
+		}
+
+		for _, group := range fileGroups {
+			index := slices.IndexFunc(groups, func(loaded *models.GroupConfiguration) bool {
+				return loaded.Name == group.Name
+			})
+			if index >= 0 {
+				groups[index] = group
+			} else {
+				groups = append(groups, group)
+			}
+		}
+	}
+
+	return
+}
@@ -0,0 +1,141 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"qrobcis/pkgsmanager/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ProfilesKey is the top-level key mapping profile names to the groups
+	// they synchronize.
+	ProfilesKey = "profiles"
+	// DefaultProfileKey is the top-level key naming the profile used when
+	// sync is given neither a profile nor groups.
+	DefaultProfileKey = "defaultProfile"
+	// RepositoriesKey is the top-level key declaring the apt repositories
+	// packages are installed from.
+	RepositoriesKey = "repositories"
+)
+
+// IsReservedKey tells whether a top-level key holds settings rather than a
+// group.
+func IsReservedKey(key string) bool {
+	return key == ProfilesKey || key == DefaultProfileKey || key == RepositoriesKey
+}
+
+// Load reads the groups of the configuration file, in the order they are
+// written in. A group is either a bare list of packages or a mapping with
+// its packages under the packages key. Reserved keys are not groups.
+func Load(path string) (groups []*models.GroupConfiguration, err error) {
+	root, err := readRoot(path)
+	if err != nil || root == nil {
+		return
+	}
+
+	declared := make(map[string]bool)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if IsReservedKey(root.Content[i].Value) {
+			continue
+		}
+		if declared[root.Content[i].Value] {
+			err = errors.New(fmt.Sprintf("%s:%d: group %s is declared twice", path, root.Content[i].Line, root.Content[i].Value))
+			return
+		}
+		declared[root.Content[i].Value] = true
+
+		var group *models.GroupConfiguration
+		group, err = decodeGroup(root.Content[i].Value, root.Content[i+1])
+		if err != nil {
+			err = errors.New(fmt.Sprintf("%s:%d: %s", path, root.Content[i].Line, err))
+			return
+		}
+		group.Origin = path
+		groups = append(groups, group)
+	}
+
+	return
+}
+
+// readRoot parses the configuration file and returns its root mapping, or nil
+// when the path is empty or the file has no content.
+func readRoot(path string) (root *yaml.Node, err error) {
+	if path == "" {
+		return
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	var document yaml.Node
+	if err = yaml.Unmarshal(content, &document); err != nil {
+		err = errors.New(fmt.Sprintf("%s: %s", path, err))
+		return
+	}
+	if len(document.Content) == 0 {
+		return
+	}
+
+	root = document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		root = nil
+		err = errors.New(fmt.Sprintf("%s:%d: the configuration must be a mapping of groups", path, document.Content[0].Line))
+	}
+
+	return
+}
+
+func decodeGroup(groupName string, node *yaml.Node) (group *models.GroupConfiguration, err error) {
+	var rawGroup models.RawGroupConfiguration
+	switch node.Kind {
+	case yaml.SequenceNode:
+		err = node.Decode(&rawGroup.Packages)
+	case yaml.MappingNode:
+		err = node.Decode(&rawGroup)
+	case yaml.ScalarNode:
+		if node.Tag != "!!null" {
+			err = errors.New(fmt.Sprintf("group %s must be a list of packages or a mapping", groupName))
+		}
+	default:
+		err = errors.New(fmt.Sprintf("group %s must be a list of packages or a mapping", groupName))
+	}
+	if err != nil {
+		return
+	}
+
+	group = models.NewGroupConfiguration(groupName)
+	group.DependsOn = rawGroup.DependsOn
+	group.When = rawGroup.When
+	for _, rawPackage := range rawGroup.Packages {
+		group.AddPackage(models.NewPackageConfiguration(rawPackage))
+	}
+
+	return
+}
@@ -0,0 +1,178 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"qrobcis/pkgsmanager/internal/models"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profiles are the named selections of groups of the configuration.
+type Profiles struct {
+	Groups  map[string][]string
+	Default string
+}
+
+// LoadProfiles reads the profiles of the configuration files in order. A
+// profile declared again in a later file replaces the earlier one, and the
+// last default profile wins.
+func LoadProfiles(paths []string) (profiles Profiles, err error) {
+	profiles.Groups = make(map[string][]string)
+
+	for _, path := range paths {
+		var root *yaml.Node
+		root, err = readRoot(path)
+		if err != nil {
+			return
+		}
+		if root == nil {
+			continue
+		}
+
+		if profilesNode := mappingValue(root, ProfilesKey); profilesNode != nil {
+			var fileProfiles map[string][]string
+			if err = profilesNode.Decode(&fileProfiles); err != nil {
+				err = errors.New(fmt.Sprintf("%s:%d: %s", path, profilesNode.Line, err))
+				return
+			}
+			for name, groups := range fileProfiles {
+				profiles.Groups[name] = groups
+			}
+		}
+		if defaultNode := mappingValue(root, DefaultProfileKey); defaultNode != nil {
+			profiles.Default = defaultNode.Value
+		}
+	}
+
+	return
+}
+
+// Names returns the names of the profiles, sorted.
+func (profiles Profiles) Names() (names []string) {
+	for name := range profiles.Groups {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return
+}
+
+// Select returns the groups to synchronize, in their order: the groups of the
+// profile and the included ones, or every group when there are none, along
+// with the groups they depend on, minus the excluded ones. Without a profile
+// nor included groups, the default profile is used when there is one.
+func Select(groups []*models.GroupConfiguration, profiles Profiles, profileName string, include []string, exclude []string) (selected []*models.GroupConfiguration, err error) {
+	if profileName == "" && len(include) == 0 {
+		profileName = profiles.Default
+	}
+	if profileName != "" {
+		profileGroups, found := profiles.Groups[profileName]
+		if !found {
+			err = errors.New(fmt.Sprintf("Profile %s is not declared, known profiles: %s", profileName, strings.Join(profiles.Names(), ", ")))
+			return
+		}
+		include = append(slices.Clone(profileGroups), include...)
+	}
+
+	byName := make(map[string]*models.GroupConfiguration)
+	for _, group := range groups {
+		byName[strings.ToLower(group.Name)] = group
+	}
+	lookup := func(groupName string) (group *models.GroupConfiguration, err error) {
+		group, found := byName[strings.ToLower(groupName)]
+		if !found {
+			err = errors.New(fmt.Sprintf("Group %s not found in the configuration", groupName))
+		}
+		return
+	}
+
+	excluded := make(map[string]bool)
+	for _, groupName := range exclude {
+		var group *models.GroupConfiguration
+		if group, err = lookup(groupName); err != nil {
+			return
+		}
+		excluded[group.Name] = true
+	}
+
+	wanted := make(map[string]bool)
+	if len(include) == 0 {
+		for _, group := range groups {
+			wanted[group.Name] = !excluded[group.Name]
+		}
+	}
+
+	// The groups a wanted group depends on are synchronized first, so they
+	// are wanted as well and can't be excluded.
+	var want func(groupName string, dependent string) error
+	want = func(groupName string, dependent string) error {
+		group, err := lookup(groupName)
+		if err != nil {
+			return err
+		}
+		if excluded[group.Name] {
+			if dependent != "" {
+				return errors.New(fmt.Sprintf("Group %s depends on group %s, which is excluded", dependent, group.Name))
+			}
+			return nil
+		}
+		if wanted[group.Name] {
+			return nil
+		}
+		wanted[group.Name] = true
+		for _, dependency := range group.DependsOn {
+			if err = want(dependency, group.Name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, groupName := range include {
+		if err = want(groupName, ""); err != nil {
+			return
+		}
+	}
+	for _, group := range groups {
+		if !wanted[group.Name] {
+			continue
+		}
+		for _, dependency := range group.DependsOn {
+			if err = want(dependency, group.Name); err != nil {
+				return
+			}
+		}
+	}
+
+	for _, group := range groups {
+		if wanted[group.Name] {
+			selected = append(selected, group)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,200 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"qrobcis/pkgsmanager/internal/models"
+	"qrobcis/pkgsmanager/internal/types/provider"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// repositoryName restricts repository names to what can be used in a
+	// file name.
+	repositoryName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	// nameSeparators are the characters replaced by dashes when a repository
+	// is named after the URI of a sourceList.
+	nameSeparators = regexp.MustCompile(`[^A-Za-z0-9.]+`)
+)
+
+// LoadRepositories reads the repositories of the configuration files in order.
+// A repository declared again in a later file replaces the earlier one.
+func LoadRepositories(paths []string) (repositories map[string]*models.Repository, err error) {
+	repositories = make(map[string]*models.Repository)
+
+	for _, path := range paths {
+		var root *yaml.Node
+		root, err = readRoot(path)
+		if err != nil {
+			return
+		}
+		if root == nil {
+			continue
+		}
+
+		repositoriesNode := mappingValue(root, RepositoriesKey)
+		if repositoriesNode == nil {
+			continue
+		}
+		var fileRepositories map[string]*models.Repository
+		if err = repositoriesNode.Decode(&fileRepositories); err != nil {
+			err = errors.New(fmt.Sprintf("%s:%d: %s", path, repositoriesNode.Line, err))
+			return
+		}
+		for name, repository := range fileRepositories {
+			if !repositoryName.MatchString(name) {
+				err = errors.New(fmt.Sprintf("%s:%d: repository name %s may only contain letters, digits, dots, dashes and underscores", path, repositoriesNode.Line, name))
+				return
+			}
+			if repository == nil || len(repository.URIs) == 0 || len(repository.Suites) == 0 {
+				err = errors.New(fmt.Sprintf("%s:%d: repository %s needs uris and suites", path, repositoriesNode.Line, name))
+				return
+			}
+			repository.Name = name
+			repositories[name] = repository
+		}
+	}
+
+	return
+}
+
+// ResolveRepositories returns the repositories the apt packages of the groups
+// are installed from: used are the ones of the packages to install on this
+// machine, referenced the names of the ones any package still refers to.
+// Packages declared with a one-line sourceList get a repository named after
+// its URI and suite, shared by the packages declaring the same source.
+func ResolveRepositories(declared map[string]*models.Repository, groups []*models.GroupConfiguration) (used []*models.Repository, referenced []string, err error) {
+	legacy := make(map[string]*models.Repository)
+
+	for _, group := range groups {
+		for _, pkgConfiguration := range group.Packages {
+			if pkgConfiguration.Provider != provider.APT {
+				if pkgConfiguration.Repository != "" {
+					err = errors.New(fmt.Sprintf("Package %s uses repository %s, repositories are only used by apt packages", pkgConfiguration.Name, pkgConfiguration.Repository))
+					return
+				}
+				continue
+			}
+			if pkgConfiguration.State.IsRemoved() {
+				continue
+			}
+
+			var repository *models.Repository
+			if pkgConfiguration.Repository != "" {
+				if pkgConfiguration.SourceList != "" {
+					err = errors.New(fmt.Sprintf("Package %s declares both a repository and a sourceList", pkgConfiguration.Name))
+					return
+				}
+				var found bool
+				if repository, found = declared[pkgConfiguration.Repository]; !found {
+					err = errors.New(fmt.Sprintf("Package %s uses repository %s, which is not declared under %s", pkgConfiguration.Name, pkgConfiguration.Repository, RepositoriesKey))
+					return
+				}
+			} else if pkgConfiguration.SourceList != "" {
+				if repository, err = legacyRepository(legacy, pkgConfiguration); err != nil {
+					return
+				}
+			} else {
+				continue
+			}
+
+			if !slices.Contains(referenced, repository.Name) {
+				referenced = append(referenced, repository.Name)
+			}
+			if pkgConfiguration.SkipReason == "" && !slices.Contains(used, repository) {
+				used = append(used, repository)
+			}
+		}
+	}
+
+	return
+}
+
+// legacyRepository converts the sourceList of a package, such as
+// "[arch=amd64] https://download.docker.com/linux/debian bookworm stable",
+// to a repository, reusing the one of an earlier package with the same source.
+func legacyRepository(legacy map[string]*models.Repository, pkgConfiguration *models.PackageConfiguration) (repository *models.Repository, err error) {
+	fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(pkgConfiguration.SourceList), "deb "))
+
+	repository = &models.Repository{}
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "[") {
+		end := slices.IndexFunc(fields, func(field string) bool {
+			return strings.HasSuffix(field, "]")
+		})
+		if end < 0 {
+			err = errors.New(fmt.Sprintf("The sourceList of %s has unclosed options", pkgConfiguration.Name))
+			return
+		}
+		for _, option := range fields[:end+1] {
+			key, value, _ := strings.Cut(strings.Trim(option, "[]"), "=")
+			switch key {
+			case "arch":
+				repository.Architectures = strings.Split(value, ",")
+			case "signed-by":
+				repository.SignedBy = value
+			}
+		}
+		fields = fields[end+1:]
+	}
+	if len(fields) < 2 {
+		err = errors.New(fmt.Sprintf("The sourceList of %s must give at least a URI and a suite", pkgConfiguration.Name))
+		return
+	}
+	repository.URIs = models.StringList{fields[0]}
+	repository.Suites = models.StringList{fields[1]}
+	if len(fields) > 2 {
+		repository.Components = fields[2:]
+	}
+	if pkgConfiguration.GPGKey != "" {
+		repository.SignedBy = pkgConfiguration.GPGKey
+	}
+	repository.GPGFingerprint = pkgConfiguration.GPGFingerprint
+
+	_, location, _ := strings.Cut(fields[0], "://")
+	repository.Name = strings.Trim(nameSeparators.ReplaceAllString(location+"-"+fields[1], "-"), "-.")
+
+	if existing, found := legacy[repository.Name]; found {
+		if !sameSource(existing, repository) {
+			err = errors.New(fmt.Sprintf("Packages %s and %s declare different sourceList entries for %s", strings.Join(existing.LegacyPackages, ", "), pkgConfiguration.Name, fields[0]))
+			return
+		}
+		repository = existing
+	} else {
+		legacy[repository.Name] = repository
+	}
+	repository.LegacyPackages = append(repository.LegacyPackages, pkgConfiguration.Name)
+
+	return
+}
+
+func sameSource(a *models.Repository, b *models.Repository) bool {
+	return slices.Equal(a.Types, b.Types) && slices.Equal(a.URIs, b.URIs) && slices.Equal(a.Suites, b.Suites) &&
+		slices.Equal(a.Components, b.Components) && slices.Equal(a.Architectures, b.Architectures) && a.SignedBy == b.SignedBy &&
+		a.GPGFingerprint == b.GPGFingerprint
+}
@@ -0,0 +1,250 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema is the JSON Schema of the configuration file.
+//
+//go:embed pkgsmanager.schema.json
+var Schema []byte
+
+// schema is the subset of JSON Schema the configuration schema is written
+// with: types, enums, patterns, required and additional properties, items and
+// references to $defs.
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 schemaTypes        `json:"type"`
+	Enum                 []string           `json:"enum"`
+	Pattern              string             `json:"pattern"`
+	MinLength            int                `json:"minLength"`
+	Required             []string           `json:"required"`
+	Properties           map[string]*schema `json:"properties"`
+	AdditionalProperties *additionalSchema  `json:"additionalProperties"`
+	Items                *schema            `json:"items"`
+	Defs                 map[string]*schema `json:"$defs"`
+}
+
+// schemaTypes is the type keyword, either a single type or a list of them.
+type schemaTypes []string
+
+func (types *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if json.Unmarshal(data, &single) == nil {
+		*types = schemaTypes{single}
+		return nil
+	}
+
+	return json.Unmarshal(data, (*[]string)(types))
+}
+
+// additionalSchema is the additionalProperties keyword, either false or the
+// schema of the properties that are not listed.
+type additionalSchema struct {
+	forbidden bool
+	schema    *schema
+}
+
+func (additional *additionalSchema) UnmarshalJSON(data []byte) error {
+	var allowed bool
+	if json.Unmarshal(data, &allowed) == nil {
+		additional.forbidden = !allowed
+		return nil
+	}
+
+	return json.Unmarshal(data, &additional.schema)
+}
+
+// schemaValidator checks a YAML document against the schema, reporting every
+// mismatch with the node it was found on.
+type schemaValidator struct {
+	root     *schema
+	patterns map[string]*regexp.Regexp
+	report   func(node *yaml.Node, message string)
+}
+
+func newSchemaValidator(report func(node *yaml.Node, message string)) (validator *schemaValidator, err error) {
+	validator = &schemaValidator{patterns: make(map[string]*regexp.Regexp), report: report}
+	err = json.Unmarshal(Schema, &validator.root)
+
+	return
+}
+
+func (validator *schemaValidator) validate(nodeSchema *schema, node *yaml.Node, location string) {
+	for nodeSchema.Ref != "" {
+		nodeSchema = validator.root.Defs[strings.TrimPrefix(nodeSchema.Ref, "#/$defs/")]
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	// Integers are numbers as well.
+	nodeType := yamlType(node)
+	if len(nodeSchema.Type) > 0 && !slices.Contains(nodeSchema.Type, nodeType) && !(nodeType == "integer" && slices.Contains(nodeSchema.Type, "number")) {
+		validator.report(node, fmt.Sprintf("%s must be %s, not %s", describeLocation(location), strings.Join(nodeSchema.Type, " or "), nodeType))
+		return
+	}
+
+	switch node.Kind {
+	case yaml.ScalarNode:
+		validator.validateScalar(nodeSchema, node, location)
+	case yaml.MappingNode:
+		validator.validateMapping(nodeSchema, node, location)
+	case yaml.SequenceNode:
+		if nodeSchema.Items != nil {
+			for i, item := range node.Content {
+				validator.validate(nodeSchema.Items, item, fmt.Sprintf("%s[%d]", location, i))
+			}
+		}
+	}
+}
+
+func (validator *schemaValidator) validateScalar(nodeSchema *schema, node *yaml.Node, location string) {
+	if len(nodeSchema.Enum) > 0 && !slices.Contains(nodeSchema.Enum, node.Value) {
+		validator.report(node, fmt.Sprintf("%s: unknown value %q, expected one of %s", location, node.Value, strings.Join(nodeSchema.Enum, ", ")))
+		return
+	}
+	if len(node.Value) < nodeSchema.MinLength {
+		validator.report(node, fmt.Sprintf("%s must not be empty", location))
+		return
+	}
+
+	if nodeSchema.Pattern != "" {
+		pattern, compiled := validator.patterns[nodeSchema.Pattern]
+		if !compiled {
+			pattern = regexp.MustCompile(nodeSchema.Pattern)
+			validator.patterns[nodeSchema.Pattern] = pattern
+		}
+		if !pattern.MatchString(node.Value) {
+			validator.report(node, fmt.Sprintf("%s: invalid value %q", location, node.Value))
+		}
+	}
+}
+
+func (validator *schemaValidator) validateMapping(nodeSchema *schema, node *yaml.Node, location string) {
+	var keys []string
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		keys = append(keys, key)
+
+		keyLocation := key
+		if location != "" {
+			keyLocation = location + "." + key
+		}
+
+		if propertySchema, found := nodeSchema.Properties[key]; found {
+			validator.validate(propertySchema, node.Content[i+1], keyLocation)
+		} else if nodeSchema.AdditionalProperties != nil && nodeSchema.AdditionalProperties.forbidden {
+			message := fmt.Sprintf("%s: unknown key %s", describeLocation(location), key)
+			if suggestion := closestProperty(key, nodeSchema.Properties); suggestion != "" {
+				message += ", did you mean " + suggestion + "?"
+			}
+			validator.report(node.Content[i], message)
+		} else if nodeSchema.AdditionalProperties != nil && nodeSchema.AdditionalProperties.schema != nil {
+			validator.validate(nodeSchema.AdditionalProperties.schema, node.Content[i+1], keyLocation)
+		}
+	}
+
+	for _, required := range nodeSchema.Required {
+		if !slices.Contains(keys, required) {
+			validator.report(node, fmt.Sprintf("%s: missing key %s", describeLocation(location), required))
+		}
+	}
+}
+
+// describeLocation names a location of the document in messages, the root
+// having an empty location.
+func describeLocation(location string) string {
+	if location == "" {
+		return "configuration"
+	}
+
+	return location
+}
+
+// yamlType returns the JSON Schema type of a YAML node.
+func yamlType(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+
+	switch node.ShortTag() {
+	case "!!null":
+		return "null"
+	case "!!bool":
+		return "boolean"
+	case "!!int":
+		return "integer"
+	case "!!float":
+		return "number"
+	}
+
+	return "string"
+}
+
+// closestProperty returns the property key is most likely a typo of, if any.
+func closestProperty(key string, properties map[string]*schema) (closest string) {
+	bestDistance := 3
+	for property := range properties {
+		if distance := editDistance(strings.ToLower(key), strings.ToLower(property)); distance < bestDistance {
+			closest, bestDistance = property, distance
+		}
+	}
+
+	return
+}
+
+// editDistance is the Levenshtein distance between two strings.
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			substitution := previous[j-1]
+			if a[i-1] != b[j-1] {
+				substitution += 1
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, substitution)
+		}
+		previous = current
+	}
+
+	return previous[len(b)]
+}
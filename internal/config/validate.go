@@ -0,0 +1,262 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is a mistake found in a configuration file, located by line and
+// column when it comes from a node of the document.
+type Problem struct {
+	Path    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (problem Problem) String() string {
+	if problem.Line == 0 {
+		return fmt.Sprintf("%s: %s", problem.Path, problem.Message)
+	}
+
+	return fmt.Sprintf("%s:%d:%d: %s", problem.Path, problem.Line, problem.Column, problem.Message)
+}
+
+// Validate checks a configuration file against the schema, then looks for
+// mistakes the schema can't express: packages declared twice in a group, GPG
+// keys without a source list, fingerprints without a key, source lists along
+// with a repository, apt and deb settings on packages of other providers, deb
+// packages without a file or downloading it without a checksum, and packages
+// depending on unknown packages of their group or on each other. An error is
+// returned only when the file can't be read; a YAML syntax error is reported
+// as a problem.
+func Validate(path string) (problems []Problem, err error) {
+	problems, _, err = validate(path)
+
+	return
+}
+
+// validate validates the configuration file and returns its root node, nil
+// when the file is empty or isn't valid YAML.
+func validate(path string) (problems []Problem, root *yaml.Node, err error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	var document yaml.Node
+	if yamlErr := yaml.Unmarshal(content, &document); yamlErr != nil {
+		problems = append(problems, Problem{Path: path, Message: yamlErr.Error()})
+		return
+	}
+	if len(document.Content) == 0 {
+		return
+	}
+
+	report := func(node *yaml.Node, message string) {
+		problems = append(problems, Problem{Path: path, Line: node.Line, Column: node.Column, Message: message})
+	}
+	validator, err := newSchemaValidator(report)
+	if err != nil {
+		return
+	}
+
+	root = document.Content[0]
+	validator.validate(validator.root, root, "")
+	if root.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if IsReservedKey(root.Content[i].Value) {
+				continue
+			}
+			checkGroup(root.Content[i].Value, root.Content[i+1], report)
+		}
+	}
+	slices.SortStableFunc(problems, func(a Problem, b Problem) int {
+		return a.Line - b.Line
+	})
+
+	return
+}
+
+// ValidateAll validates every configuration file, then checks the groups of
+// all the files depend on known groups and not on each other. As when the
+// files are loaded, a group declared again replaces the earlier declaration.
+func ValidateAll(paths []string) (problems []Problem, err error) {
+	var groupNames []string
+	groupPaths := make(map[string]string)
+	dependsOnNodes := make(map[string]*yaml.Node)
+	for _, path := range paths {
+		var fileProblems []Problem
+		var root *yaml.Node
+		fileProblems, root, err = validate(path)
+		if err != nil {
+			return
+		}
+		problems = append(problems, fileProblems...)
+
+		if root == nil || root.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			groupName, groupNode := root.Content[i].Value, root.Content[i+1]
+			if IsReservedKey(groupName) {
+				continue
+			}
+			if _, found := groupPaths[groupName]; !found {
+				groupNames = append(groupNames, groupName)
+			}
+			groupPaths[groupName] = path
+			dependsOnNodes[groupName] = nil
+			if groupNode.Kind == yaml.MappingNode {
+				dependsOnNodes[groupName] = mappingValue(groupNode, "dependsOn")
+			}
+		}
+	}
+
+	checkDependencies("", "group", groupNames, dependsOnNodes, func(groupName string, node *yaml.Node, message string) {
+		problems = append(problems, Problem{Path: groupPaths[groupName], Line: node.Line, Column: node.Column, Message: message})
+	})
+
+	return
+}
+
+// checkDependencies reports the dependencies of the names on unknown names
+// and the first dependency cycle found, as Order would fail on them. The
+// dependencies are read from the dependsOn node of each name.
+func checkDependencies(prefix string, kind string, names []string, dependsOnNodes map[string]*yaml.Node, report func(name string, node *yaml.Node, message string)) {
+	dependencies := make(map[string][]string)
+	for _, name := range names {
+		dependencies[name] = nil
+	}
+	for _, name := range names {
+		dependsOnNode := dependsOnNodes[name]
+		if dependsOnNode == nil || dependsOnNode.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, dependencyNode := range dependsOnNode.Content {
+			if _, found := dependencies[dependencyNode.Value]; !found {
+				report(name, dependencyNode, fmt.Sprintf("%s%s %s depends on unknown %s %s", prefix, kind, name, kind, dependencyNode.Value))
+				continue
+			}
+			dependencies[name] = append(dependencies[name], dependencyNode.Value)
+		}
+	}
+
+	_, err := topologicalOrder(kind, names, dependencies)
+	var cycleErr *cycleError
+	if errors.As(err, &cycleErr) {
+		name := cycleErr.cycle[0]
+		report(name, dependsOnNodes[name], prefix+err.Error())
+	}
+}
+
+func checkGroup(groupName string, groupNode *yaml.Node, report func(node *yaml.Node, message string)) {
+	packagesNode := groupNode
+	if groupNode.Kind == yaml.MappingNode {
+		packagesNode = mappingValue(groupNode, "packages")
+	}
+	if packagesNode == nil || packagesNode.Kind != yaml.SequenceNode {
+		return
+	}
+
+	declared := make(map[string]int)
+	var packageNames []string
+	dependsOnNodes := make(map[string]*yaml.Node)
+	for _, packageNode := range packagesNode.Content {
+		if packageNode.Kind != yaml.MappingNode {
+			continue
+		}
+
+		if nameNode := mappingValue(packageNode, "name"); nameNode != nil && nameNode.Value != "" {
+			if line, found := declared[nameNode.Value]; found {
+				report(nameNode, fmt.Sprintf("%s: package %s is already declared on line %d", groupName, nameNode.Value, line))
+			} else {
+				declared[nameNode.Value] = nameNode.Line
+				packageNames = append(packageNames, nameNode.Value)
+				dependsOnNodes[nameNode.Value] = mappingValue(packageNode, "dependsOn")
+			}
+		}
+
+		providerNode := mappingValue(packageNode, "provider")
+		if providerNode != nil && providerNode.Value != "apt" && providerNode.Value != "system" {
+			for _, key := range []string{"repository", "sourceList", "souceList", "apt"} {
+				if keyNode := mappingValue(packageNode, key); keyNode != nil {
+					report(keyNode, fmt.Sprintf("%s: %s is only used by apt packages", groupName, key))
+				}
+			}
+		}
+		debNode := mappingValue(packageNode, "deb")
+		if providerNode != nil && providerNode.Value == "deb" && (debNode == nil || mappingValue(debNode, "url") == nil) {
+			report(providerNode, fmt.Sprintf("%s: deb packages need a deb url", groupName))
+		} else if debNode != nil && (providerNode == nil || providerNode.Value != "deb") {
+			report(debNode, fmt.Sprintf("%s: deb is only used by deb packages", groupName))
+		}
+		if debNode != nil && mappingValue(debNode, "sha256") == nil {
+			if urlNode := mappingValue(debNode, "url"); urlNode != nil && strings.Contains(urlNode.Value, "://") {
+				report(urlNode, fmt.Sprintf("%s: deb files downloaded from a URL need a sha256", groupName))
+			}
+		}
+
+		sourceListNode := mappingValue(packageNode, "sourceList")
+		if legacyNode := mappingValue(packageNode, "souceList"); legacyNode != nil && sourceListNode != nil {
+			report(legacyNode, fmt.Sprintf("%s: souceList is the deprecated spelling of sourceList, keep only sourceList", groupName))
+		} else if legacyNode != nil {
+			sourceListNode = legacyNode
+		}
+
+		repositoryNode := mappingValue(packageNode, "repository")
+		if repositoryNode != nil && sourceListNode != nil {
+			report(sourceListNode, fmt.Sprintf("%s: sourceList can't be used along with a repository", groupName))
+		}
+
+		gpgKeyNode := mappingValue(packageNode, "gpgKey")
+		if gpgKeyNode != nil && (sourceListNode == nil || strings.TrimSpace(sourceListNode.Value) == "") {
+			report(gpgKeyNode, fmt.Sprintf("%s: gpgKey is only used with a sourceList", groupName))
+		}
+		if fingerprintNode := mappingValue(packageNode, "gpgFingerprint"); fingerprintNode != nil && gpgKeyNode == nil {
+			report(fingerprintNode, fmt.Sprintf("%s: gpgFingerprint is only used with a gpgKey", groupName))
+		}
+	}
+
+	checkDependencies(groupName+": ", "package", packageNames, dependsOnNodes, func(_ string, node *yaml.Node, message string) {
+		report(node, message)
+	})
+}
+
+// mappingValue returns the value of key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestValidateAllDependencies(t *testing.T) {
+	directory := t.TempDir()
+	system := filepath.Join(directory, "system.yaml")
+	user := filepath.Join(directory, "user.yaml")
+	files := map[string]string{
+		system: `base:
+  dependsOn: [tools]
+  packages: []
+`,
+		user: `dev:
+  dependsOn: [base, missing]
+  packages:
+    - name: a
+      dependsOn: [b]
+    - name: b
+      dependsOn: [a]
+    - name: c
+      dependsOn: [unknown]
+tools:
+  dependsOn: [base]
+  packages: []
+`,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	problems, err := ValidateAll([]string{system, user})
+	if err != nil {
+		t.Fatalf("ValidateAll() error = %v", err)
+	}
+	var reported []string
+	for _, problem := range problems {
+		reported = append(reported, problem.String())
+	}
+	want := []string{
+		user + ":5:18: dev: package dependency cycle: a -> b -> a",
+		user + ":9:19: dev: package c depends on unknown package unknown",
+		system + ":2:14: group dependency cycle: base -> tools -> base",
+		user + ":2:21: group dev depends on unknown group missing",
+	}
+	slices.Sort(reported)
+	slices.Sort(want)
+	if !slices.Equal(reported, want) {
+		t.Fatalf("ValidateAll() reported %q, want %q", reported, want)
+	}
+}
+
+func TestValidateDeprecatedSourceList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `dev:
+  - name: code
+    souceList: https://packages.microsoft.com/repos/code stable main
+  - name: edge
+    souceList: https://packages.microsoft.com/repos/edge stable main
+    sourceList: https://packages.microsoft.com/repos/edge stable main
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	problems, err := Validate(path)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	want := path + ":5:16: dev: souceList is the deprecated spelling of sourceList, keep only sourceList"
+	if len(problems) != 1 || problems[0].String() != want {
+		t.Fatalf("Validate() reported %v, want only %q", problems, want)
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lock
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"qrobcis/pkgsmanager/internal/types/provider"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the lockfile, written next to the configuration file.
+const FileName = ".pkgsmanager.lock"
+
+const header = "# Generated by pkgsmanager from the installed packages, do not edit.\n# Refresh it with: pkgsmanager lock update\n"
+
+// LockedPackage is the version of a package resolved on the machine the
+// lockfile was written on. Repository and Architecture are only known for apt.
+type LockedPackage struct {
+	Group        string            `yaml:"group"`
+	Name         string            `yaml:"name"`
+	Provider     provider.Provider `yaml:"provider"`
+	Version      string            `yaml:"version"`
+	Repository   string            `yaml:"repository,omitempty"`
+	Architecture string            `yaml:"architecture,omitempty"`
+}
+
+type Lockfile struct {
+	Packages []LockedPackage `yaml:"packages"`
+}
+
+// PathFor returns the path of the lockfile of a configuration file.
+func PathFor(configurationPath string) string {
+	return filepath.Join(filepath.Dir(configurationPath), FileName)
+}
+
+// Read loads a lockfile. A missing file is reported as an error matching
+// os.ErrNotExist.
+func Read(path string) (lockfile *Lockfile, err error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	lockfile = &Lockfile{}
+	if err = yaml.Unmarshal(content, lockfile); err != nil {
+		err = errors.New(fmt.Sprintf("%s: %s", path, err))
+	}
+
+	return
+}
+
+// Write saves the lockfile, replacing the previous one.
+func (lockfile *Lockfile) Write(path string) (err error) {
+	content := bytes.NewBufferString(header)
+	encoder := yaml.NewEncoder(content)
+	encoder.SetIndent(2)
+	if err = encoder.Encode(lockfile); err != nil {
+		return
+	}
+
+	return os.WriteFile(path, content.Bytes(), 0644)
+}
+
+// Find returns the locked version of a package of a group.
+func (lockfile *Lockfile) Find(groupName string, packageName string) (lockedPackage LockedPackage, found bool) {
+	for _, lockedPackage = range lockfile.Packages {
+		if lockedPackage.Group == groupName && lockedPackage.Name == packageName {
+			return lockedPackage, true
+		}
+	}
+
+	return LockedPackage{}, false
+}
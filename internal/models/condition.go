@@ -0,0 +1,54 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package models
+
+import (
+    "gopkg.in/yaml.v3"
+)
+
+// StringList is a list of strings that may be written as a single string in
+// the configuration file.
+type StringList []string
+
+func (list *StringList) UnmarshalYAML(node *yaml.Node) error {
+    if node.Kind == yaml.ScalarNode {
+        *list = StringList{node.Value}
+        return nil
+    }
+
+    return node.Decode((*[]string)(list))
+}
+
+// Condition limits a group or a package to the machines it matches. Every
+// field that is set must match, and a list matches when any of its entries
+// does. Hostnames and OS versions are globs, OS is the ID of os-release or one
+// of the distributions it derives from, and Env maps variables that must be
+// set to a glob of their value.
+type Condition struct {
+    Hostname  StringList        `yaml:"hostname,omitempty"`
+    OS        StringList        `yaml:"os,omitempty"`
+    OSVersion StringList        `yaml:"osVersion,omitempty"`
+    Arch      StringList        `yaml:"arch,omitempty"`
+    Env       map[string]string `yaml:"env,omitempty"`
+    Command   StringList        `yaml:"command,omitempty"`
+}
@@ -26,27 +26,62 @@ import (
     "qrobcis/pkgsmanager/internal/types/provider"
 )
 
+// RawGroupConfiguration is the long form of a group in the configuration
+// file, used when the group has settings of its own. The short form is the
+// bare list of packages.
+type RawGroupConfiguration struct {
+    DependsOn []string                  `yaml:"dependsOn"`
+    When      *Condition                `yaml:"when"`
+    Packages  []RawPackageConfiguration `yaml:"packages"`
+}
+
+// GroupConfiguration is a group of packages along with Origin, the path of
+// the configuration file declaring it.
 type GroupConfiguration struct {
-    Name     string
-    Packages map[string]*PackageConfiguration
+    Name      string
+    Origin    string
+    DependsOn []string
+    When      *Condition
+    Packages  []*PackageConfiguration
 }
 
 func NewGroupConfiguration(name string) *GroupConfiguration {
     return &GroupConfiguration{
         Name:     name,
-        Packages: make(map[string]*PackageConfiguration),
+        Packages: make([]*PackageConfiguration, 0),
     }
 }
 
+// AddPackage appends the package to the group, replacing in place a package
+// already declared with the same name.
 func (group *GroupConfiguration) AddPackage(configuration *PackageConfiguration) {
-    group.Packages[configuration.Name] = configuration
+    for i, existing := range group.Packages {
+        if existing.Name == configuration.Name {
+            group.Packages[i] = configuration
+            return
+        }
+    }
+
+    group.Packages = append(group.Packages, configuration)
+}
+
+func (group *GroupConfiguration) Package(name string) (configuration *PackageConfiguration, found bool) {
+    for _, candidate := range group.Packages {
+        if candidate.Name == name {
+            return candidate, true
+        }
+    }
+
+    return
 }
 
+// HasProvider tells whether a package of the group, not skipped on this
+// machine, uses the provider.
 func (group *GroupConfiguration) HasProvider(provider provider.Provider) (hasProvider bool) {
     hasProvider = false
 
     for _, configuration := range group.Packages {
-        if configuration.Provider == provider {
+        if configuration.Provider == provider && configuration.SkipReason == "" {
             hasProvider = true
             return
         }
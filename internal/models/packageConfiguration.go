@@ -22,35 +22,125 @@ THE SOFTWARE.
 
 package models
 
-import "qrobcis/pkgsmanager/internal/types/provider"
+import (
+    "qrobcis/pkgsmanager/internal/types/provider"
+    "qrobcis/pkgsmanager/internal/types/state"
+)
+
+// AptOptions install an apt package from another release than the default
+// one: Release is the target release given to apt-get install, Pin and
+// Priority are written to an apt preferences file. Hold keeps the package at
+// its installed version, by default when an exact version is requested.
+type AptOptions struct {
+    Release  string `yaml:"release,omitempty"`
+    Pin      string `yaml:"pin,omitempty"`
+    Priority int    `yaml:"priority,omitempty"`
+    Hold     *bool  `yaml:"hold,omitempty"`
+}
+
+// DebOptions locate the .deb file of a deb package: URL is its address or its
+// path on the machine, SHA256 its checksum.
+type DebOptions struct {
+    URL    string `yaml:"url,omitempty"`
+    SHA256 string `yaml:"sha256,omitempty"`
+}
+
+// PipOptions selects how a pip package is installed: in the user site of the
+// interpreter, in a virtual environment or as an isolated pipx application.
+type PipOptions struct {
+    Mode        string `yaml:"mode,omitempty"`
+    Interpreter string `yaml:"interpreter,omitempty"`
+    Venv        string `yaml:"venv,omitempty"`
+}
+
+// CargoOptions are passed to cargo install.
+type CargoOptions struct {
+    Locked   bool     `yaml:"locked,omitempty"`
+    Git      string   `yaml:"git,omitempty"`
+    Features []string `yaml:"features,omitempty"`
+}
+
+// FlatpakOptions select the remote an application comes from, declared with
+// its URL so it can be added when missing, and the installation scope.
+type FlatpakOptions struct {
+    Remote    string `yaml:"remote,omitempty"`
+    RemoteURL string `yaml:"remoteUrl,omitempty"`
+    Scope     string `yaml:"scope,omitempty"`
+}
 
 type RawPackageConfiguration struct {
-    Name       string `yaml:"name"`
-    GPGKey     string `yaml:"gpgKey"`
-    SourceList string `yaml:"souceList"`
-    Provider   string `yaml:"provider"`
-    Version    string `yaml:"version"`
+    Name           string         `yaml:"name"`
+    GPGKey         string         `yaml:"gpgKey"`
+    GPGFingerprint string         `yaml:"gpgFingerprint"`
+    SourceList     string         `yaml:"sourceList"`
+    // SouceList is the misspelled key sourceList was once read from, still
+    // accepted so that older configuration files keep loading.
+    SouceList      string         `yaml:"souceList"`
+    Repository     string         `yaml:"repository"`
+    Provider       string         `yaml:"provider"`
+    Version        string         `yaml:"version"`
+    State          string         `yaml:"state"`
+    DependsOn      []string       `yaml:"dependsOn"`
+    When           *Condition     `yaml:"when"`
+    Apt            AptOptions     `yaml:"apt"`
+    Deb            DebOptions     `yaml:"deb"`
+    Pip            PipOptions     `yaml:"pip"`
+    Cargo          CargoOptions   `yaml:"cargo"`
+    Flatpak        FlatpakOptions `yaml:"flatpak"`
 }
 
 type PackageConfiguration struct {
-    Name       string            `yaml:"name"`
-    GPGKey     string            `yaml:"gpgKey"`
-    SourceList string            `yaml:"souceList"`
-    Provider   provider.Provider `yaml:"provider"`
-    Version    string            `yaml:"version"`
+    Name           string            `yaml:"name"`
+    GPGKey         string            `yaml:"gpgKey,omitempty"`
+    GPGFingerprint string            `yaml:"gpgFingerprint,omitempty"`
+    SourceList     string            `yaml:"sourceList,omitempty"`
+    Repository     string            `yaml:"repository,omitempty"`
+    Provider       provider.Provider `yaml:"provider,omitempty"`
+    Version        string            `yaml:"version,omitempty"`
+    State          state.State       `yaml:"state,omitempty"`
+    DependsOn      []string          `yaml:"dependsOn,omitempty"`
+    When           *Condition        `yaml:"when,omitempty"`
+    Apt            AptOptions        `yaml:"apt,omitempty"`
+    Deb            DebOptions        `yaml:"deb,omitempty"`
+    Pip            PipOptions        `yaml:"pip,omitempty"`
+    Cargo          CargoOptions      `yaml:"cargo,omitempty"`
+    Flatpak        FlatpakOptions    `yaml:"flatpak,omitempty"`
+    // ConfiguredVersion is the version written in the configuration. Version
+    // is the one to install, which sync --frozen replaces by the version of
+    // the lockfile.
+    ConfiguredVersion string `yaml:"-"`
+    // SkipReason tells why the package is skipped on this machine, when its
+    // conditions or the ones of its group don't match.
+    SkipReason string `yaml:"-"`
 }
 
-func NewPackageConfiguration(name string, gpgKey string, souceList string, providerName string, version string) *PackageConfiguration {
-    providerValue := provider.ToProvider(providerName)
+func NewPackageConfiguration(raw RawPackageConfiguration) *PackageConfiguration {
+    providerValue := provider.ToProvider(raw.Provider)
     if providerValue == provider.Unset {
         providerValue = provider.APT
     }
 
+    sourceList := raw.SourceList
+    if sourceList == "" {
+        sourceList = raw.SouceList
+    }
+
     return &PackageConfiguration{
-        GPGKey:     gpgKey,
-        Name:       name,
-        Provider:   providerValue,
-        Version:    version,
-        SourceList: souceList,
+        GPGKey:            raw.GPGKey,
+        GPGFingerprint:    raw.GPGFingerprint,
+        Name:              raw.Name,
+        Provider:          providerValue,
+        Version:           raw.Version,
+        ConfiguredVersion: raw.Version,
+        SourceList:        sourceList,
+        Repository:        raw.Repository,
+        State:             state.ToState(raw.State),
+        DependsOn:         raw.DependsOn,
+        When:              raw.When,
+        Apt:               raw.Apt,
+        Deb:               raw.Deb,
+        Pip:               raw.Pip,
+        Cargo:             raw.Cargo,
+        Flatpak:           raw.Flatpak,
     }
 }
@@ -0,0 +1,41 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package models
+
+// Repository is an apt repository declared under the repositories key of the
+// configuration, written as a deb822 .sources file. SignedBy is the path of
+// a keyring on the machine or the URL of the key to download, checked against
+// GPGFingerprint when it is set.
+type Repository struct {
+    Name           string     `yaml:"-"`
+    Types          StringList `yaml:"types,omitempty"`
+    URIs           StringList `yaml:"uris"`
+    Suites         StringList `yaml:"suites"`
+    Components     StringList `yaml:"components,omitempty"`
+    Architectures  StringList `yaml:"architectures,omitempty"`
+    SignedBy       string     `yaml:"signedBy,omitempty"`
+    GPGFingerprint string     `yaml:"gpgFingerprint,omitempty"`
+    // LegacyPackages are the packages the repository was converted from,
+    // declared with a one-line sourceList.
+    LegacyPackages []string `yaml:"-"`
+}
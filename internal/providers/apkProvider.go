@@ -0,0 +1,151 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"qrobcis/pkgsmanager/internal/models"
+	"strings"
+)
+
+type ApkProvider struct {
+	*AbstractProvider
+}
+
+func (apk *ApkProvider) InstallPackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	packageNameVersionned := pkgConfiguration.Name
+	if pkgConfiguration.Version != "" {
+		packageNameVersionned = pkgConfiguration.Name + apk.VersionSeparator + pkgConfiguration.Version
+	}
+
+	name, args := apk.buildCommand(apk.InstallCommand, packageNameVersionned)
+	stderr, err := apk.runCommand(PlannedStep{Description: "Install " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to install %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (apk *ApkProvider) RemovePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	name, args := apk.buildCommand(apk.RemoveCommand, pkgConfiguration.Name)
+	stderr, err := apk.runCommand(PlannedStep{Description: "Remove " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to remove %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (apk *ApkProvider) UpgradePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	name, args := apk.buildCommand(apk.UpgradeCommand, pkgConfiguration.Name)
+	stderr, err := apk.runCommand(PlannedStep{Description: "Upgrade " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to upgrade %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (apk *ApkProvider) UpdateRegistry() (err error, cmdErr error) {
+	name, args := apk.buildCommand(apk.UpdateCommand)
+	stderr, err := apk.runCommand(PlannedStep{Description: "Update " + apk.Command + " registry"}, name, args...)
+	if err != nil {
+		err = errors.New("failed to update apk indexes")
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+// CleanRegistry does nothing: apk keeps no package cache unless one is
+// explicitly configured.
+func (apk *ApkProvider) CleanRegistry() (err error, cmdErr error) {
+	return
+}
+
+func (apk *ApkProvider) IsInstalled(pkgConfiguration *models.PackageConfiguration) (installed bool, err error) {
+	version, err := apk.InstalledVersion(pkgConfiguration)
+	installed = version != ""
+
+	return
+}
+
+// InstalledVersion parses the "name-1.2.3-r0 x86_64 {origin} (license) [installed]"
+// line printed by apk list.
+func (apk *ApkProvider) InstalledVersion(pkgConfiguration *models.PackageConfiguration) (version string, err error) {
+	stdout, err := apk.queryCommand(apk.Command, "list", "--installed", pkgConfiguration.Name)
+	if err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(string(stdout), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		installedVersion, found := strings.CutPrefix(fields[0], pkgConfiguration.Name+"-")
+		if found && installedVersion != "" && installedVersion[0] >= '0' && installedVersion[0] <= '9' {
+			version = installedVersion
+			break
+		}
+	}
+
+	return
+}
+
+func (apk *ApkProvider) buildCommand(subCommand string, options ...string) (name string, args []string) {
+	name = apk.Command
+	if apk.RequiresRoot == true {
+		name = "sudo"
+		args = append(args, apk.Command)
+	}
+	args = append(args, subCommand)
+
+	if len(options) > 0 {
+		args = append(args, options...)
+	}
+
+	return
+}
+
+func NewApkProvider() *ApkProvider {
+	return &ApkProvider{
+		&AbstractProvider{
+			Command:          "apk",
+			InstallCommand:   "add",
+			RemoveCommand:    "del",
+			UpdateCommand:    "update",
+			UpgradeCommand:   "upgrade",
+			CleanCommand:     "",
+			RequiresRoot:     true,
+			Lock:             "apk",
+			Executor:         NewExecExecutor(),
+			VersionSeparator: "=",
+		},
+	}
+}
@@ -23,36 +23,78 @@ THE SOFTWARE.
 package providers
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"qrobcis/pkgsmanager/internal/models"
+	"qrobcis/pkgsmanager/internal/types/state"
+	"slices"
 	"strings"
 )
 
 type AptProvider struct {
 	*AbstractProvider
+	// HoldsRecord lists the packages held by pkgsmanager, aptHoldsRecord by
+	// default.
+	HoldsRecord string
 }
 
+// InstallPackage installs the package, from its repository when it has one:
+// repositories are written by SyncRepositories before the registry update.
 func (apt *AptProvider) InstallPackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
-	if pkgConfiguration.SourceList != "" {
-		err, cmdErr = apt.addSourceList(pkgConfiguration)
-		if err != nil || cmdErr != nil {
-			return
-		}
+	packageNameVersionned := pkgConfiguration.Name
+	if pkgConfiguration.Version != "" && pkgConfiguration.Version != "latest" {
+		packageNameVersionned = pkgConfiguration.Name + apt.VersionSeparator + pkgConfiguration.Version
 	}
 
-	name, args := apt.buildCommand(apt.InstallCommand, true, pkgConfiguration.Name)
-	cmd := exec.Command(name, args...)
+	// A package whose hold is managed may be held at another version.
+	options := apt.releaseOptions(pkgConfiguration)
+	managedHolds, err := apt.ListManagedHolds()
+	if err != nil {
+		return
+	}
+	if _, managed := ShouldHold(pkgConfiguration, managedHolds); managed {
+		options = append(options, "--allow-change-held-packages")
+	}
 
-	errBuffer := new(bytes.Buffer)
-	cmd.Stderr = errBuffer
-	err = cmd.Run()
+	name, args := apt.buildCommand(apt.InstallCommand, true, append(options, packageNameVersionned)...)
+	stderr, err := apt.runCommand(PlannedStep{Description: "Install " + pkgConfiguration.Name}, name, args...)
 	if err != nil {
 		err = errors.New(fmt.Sprintf("Failed to install %s", pkgConfiguration.Name))
-		cmdErr = errors.New(errBuffer.String())
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+// RemovePackage runs apt-get remove, or apt-get purge when the package state
+// asks for its configuration files to be deleted as well. The package is
+// removed even when it is held.
+func (apt *AptProvider) RemovePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	subCommand := apt.RemoveCommand
+	if pkgConfiguration.State == state.Purged {
+		subCommand = "purge"
+	}
+
+	name, args := apt.buildCommand(subCommand, true, "--allow-change-held-packages", pkgConfiguration.Name)
+	stderr, err := apt.runCommand(PlannedStep{Description: "Remove " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to remove %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+// UpgradePackage installs the candidate version of the package, without
+// installing it when it is missing.
+func (apt *AptProvider) UpgradePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	name, args := apt.buildCommand(apt.UpgradeCommand, true, append(apt.releaseOptions(pkgConfiguration), "--only-upgrade", pkgConfiguration.Name)...)
+	stderr, err := apt.runCommand(PlannedStep{Description: "Upgrade " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to upgrade %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
 	}
 
 	return
@@ -60,13 +102,10 @@ func (apt *AptProvider) InstallPackage(pkgConfiguration *models.PackageConfigura
 
 func (apt *AptProvider) UpdateRegistry() (err error, cmdErr error) {
 	name, args := apt.buildCommand(apt.UpdateCommand, false)
-	cmd := exec.Command(name, args...)
-	errBuffer := new(bytes.Buffer)
-	cmd.Stderr = errBuffer
-	err = cmd.Run()
+	stderr, err := apt.runCommand(PlannedStep{Description: "Update " + apt.Command + " registry"}, name, args...)
 	if err != nil {
 		err = errors.New("failed to update apt sources")
-		cmdErr = errors.New(errBuffer.String())
+		cmdErr = errors.New(stderr)
 	}
 
 	return
@@ -74,13 +113,258 @@ func (apt *AptProvider) UpdateRegistry() (err error, cmdErr error) {
 
 func (apt *AptProvider) CleanRegistry() (err error, cmdErr error) {
 	name, args := apt.buildCommand(apt.CleanCommand, true)
-	cmd := exec.Command(name, args...)
-	errBuffer := new(bytes.Buffer)
-	cmd.Stderr = errBuffer
-	err = cmd.Run()
+	stderr, err := apt.runCommand(PlannedStep{Description: "Clean " + apt.Command + " registry"}, name, args...)
 	if err != nil {
 		err = errors.New("failed to update apt sources")
-		cmdErr = errors.New(errBuffer.String())
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+// IsInstalled also reports a package to purge as installed while dpkg keeps
+// its configuration files, the "rc" status of a removed package, so that it
+// gets purged.
+func (apt *AptProvider) IsInstalled(pkgConfiguration *models.PackageConfiguration) (installed bool, err error) {
+	status, version, err := dpkgStatus(apt.AbstractProvider, pkgConfiguration.Name)
+	installed = isInstalledStatus(status) && version != ""
+	if pkgConfiguration.State == state.Purged && hasConfigFilesStatus(status) {
+		installed = true
+	}
+
+	return
+}
+
+func (apt *AptProvider) InstalledVersion(pkgConfiguration *models.PackageConfiguration) (version string, err error) {
+	return dpkgInstalledVersion(apt.AbstractProvider, pkgConfiguration.Name)
+}
+
+// dpkgInstalledVersion returns the version of the package installed according
+// to dpkg, or an empty string when it is not installed.
+func dpkgInstalledVersion(provider *AbstractProvider, packageName string) (version string, err error) {
+	status, version, err := dpkgStatus(provider, packageName)
+	if !isInstalledStatus(status) {
+		version = ""
+	}
+
+	return
+}
+
+// dpkgStatus returns the status abbreviation and the version of the package
+// according to dpkg, both empty when the package is unknown to dpkg.
+func dpkgStatus(provider *AbstractProvider, packageName string) (status string, version string, err error) {
+	stdout, queryErr := provider.queryCommand("dpkg-query", "-W", "-f=${db:Status-Abbrev}|${Version}", packageName)
+	if queryErr != nil {
+		// dpkg-query exits with status 1 when the package is unknown to dpkg.
+		var exitErr *ExitError
+		if !errors.As(queryErr, &exitErr) {
+			err = queryErr
+		}
+		return
+	}
+
+	status, version, found := strings.Cut(string(stdout), "|")
+	if !found {
+		return "", "", nil
+	}
+	version = strings.TrimSpace(version)
+
+	return
+}
+
+// isInstalledStatus tells whether a dpkg status abbreviation, such as "ii " or
+// "hi " for a held package, is the one of an installed package. The first
+// letter is the selection, the second the state of the package.
+func isInstalledStatus(status string) bool {
+	return len(status) >= 2 && (status[0] == 'i' || status[0] == 'h') && status[1] == 'i'
+}
+
+// hasConfigFilesStatus tells whether a dpkg status abbreviation, such as "rc ",
+// is the one of a removed package whose configuration files are left.
+func hasConfigFilesStatus(status string) bool {
+	return len(status) >= 2 && status[1] == 'c'
+}
+
+// LockPackage records the installed version of the package along with its
+// architecture and the repository it was installed from, as shown by
+// apt-cache policy. The repository is empty for packages installed from a
+// local file.
+func (apt *AptProvider) LockPackage(pkgConfiguration *models.PackageConfiguration) (locked LockedVersion, err error) {
+	stdout, err := apt.queryCommand("dpkg-query", "-W", "-f=${Version}|${Architecture}", pkgConfiguration.Name)
+	if err != nil {
+		return
+	}
+	locked.Version, locked.Architecture, _ = strings.Cut(strings.TrimSpace(string(stdout)), "|")
+
+	stdout, err = apt.queryCommand("apt-cache", "policy", pkgConfiguration.Name)
+	if err != nil {
+		return
+	}
+	locked.Repository = installedRepository(string(stdout))
+
+	return
+}
+
+// installedRepository reads the repository of the installed version, marked
+// with ***, from the version table printed by apt-cache policy:
+//
+//	*** 7.88.1-10 500
+//	       500 http://deb.debian.org/debian bookworm/main amd64 Packages
+//	       100 /var/lib/dpkg/status
+func installedRepository(policy string) (repository string) {
+	installedVersion := false
+	for _, line := range strings.Split(policy, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[0] == "***" {
+			installedVersion = true
+			continue
+		}
+		if !installedVersion || len(fields) < 2 {
+			continue
+		}
+
+		if strings.Contains(fields[1], "://") && len(fields) >= 3 {
+			return fields[1] + " " + fields[2]
+		} else if fields[1] != "/var/lib/dpkg/status" {
+			// The sources of the installed version are listed, next comes
+			// another version.
+			return
+		}
+	}
+
+	return
+}
+
+// ListHeld returns the packages held with apt-mark.
+func (apt *AptProvider) ListHeld() (names []string, err error) {
+	stdout, err := apt.queryCommand("apt-mark", "showhold")
+	if err != nil {
+		err = errors.New("Failed to list the held packages")
+		return
+	}
+	names = strings.Fields(string(stdout))
+
+	return
+}
+
+// ListManagedHolds returns the packages held by pkgsmanager, as recorded in
+// HoldsRecord.
+func (apt *AptProvider) ListManagedHolds() (names []string, err error) {
+	content, err := os.ReadFile(apt.HoldsRecord)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to read %s: %s", apt.HoldsRecord, err))
+		return
+	}
+	names = strings.Fields(string(content))
+
+	return
+}
+
+func (apt *AptProvider) HoldPackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	if err, cmdErr = apt.markPackage("hold", pkgConfiguration); err != nil {
+		return
+	}
+
+	return apt.recordHold(pkgConfiguration.Name, true)
+}
+
+func (apt *AptProvider) UnholdPackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	if err, cmdErr = apt.markPackage("unhold", pkgConfiguration); err != nil {
+		return
+	}
+
+	return apt.recordHold(pkgConfiguration.Name, false)
+}
+
+// recordHold adds the package to the holds recorded in HoldsRecord, or
+// removes it, so that the packages held by pkgsmanager can be released once
+// the configuration no longer holds them.
+func (apt *AptProvider) recordHold(packageName string, hold bool) (err error, cmdErr error) {
+	names, err := apt.ListManagedHolds()
+	if err != nil || slices.Contains(names, packageName) == hold {
+		return
+	}
+	if hold {
+		names = append(names, packageName)
+		slices.Sort(names)
+	} else {
+		names = slices.DeleteFunc(names, func(name string) bool {
+			return name == packageName
+		})
+	}
+
+	if err, cmdErr = apt.createDirectory(filepath.Dir(apt.HoldsRecord)); err != nil {
+		return
+	}
+	content := ""
+	if len(names) > 0 {
+		content = strings.Join(names, "\n") + "\n"
+	}
+	stderr, err := apt.runCommand(PlannedStep{
+		Description: "Record the packages held by pkgsmanager",
+		File:        apt.HoldsRecord,
+		Content:     content,
+	}, "sudo", "tee", apt.HoldsRecord)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to write %s", apt.HoldsRecord))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (apt *AptProvider) markPackage(mark string, pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	name, args := "apt-mark", []string{mark, pkgConfiguration.Name}
+	if apt.RequiresRoot {
+		name, args = "sudo", append([]string{"apt-mark"}, args...)
+	}
+
+	stderr, err := apt.runCommand(PlannedStep{Description: strings.ToUpper(mark[:1]) + mark[1:] + " " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to %s %s", mark, pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+// ListInstalled returns the packages marked as manually installed, leaving
+// out the ones pulled in as dependencies.
+func (apt *AptProvider) ListInstalled() (packages []InstalledPackage, err error) {
+	stdout, err := apt.queryCommand("apt-mark", "showmanual")
+	if err != nil {
+		return
+	}
+	manual := strings.Fields(string(stdout))
+
+	stdout, err = apt.queryCommand("dpkg-query", "-W", "-f=${db:Status-Abbrev}|${Package}|${Version}\n")
+	if err != nil {
+		return
+	}
+	versions := make(map[string]string)
+	for _, line := range strings.Split(string(stdout), "\n") {
+		columns := strings.Split(line, "|")
+		if len(columns) == 3 && isInstalledStatus(columns[0]) {
+			versions[columns[1]] = columns[2]
+		}
+	}
+
+	for _, name := range manual {
+		if version, installed := versions[name]; installed {
+			packages = append(packages, InstalledPackage{Name: name, Version: version})
+		}
+	}
+
+	return
+}
+
+// releaseOptions returns the option selecting the target release of the
+// package, if it has one.
+func (apt *AptProvider) releaseOptions(pkgConfiguration *models.PackageConfiguration) (options []string) {
+	if pkgConfiguration.Apt.Release != "" {
+		options = []string{"-t", pkgConfiguration.Apt.Release}
 	}
 
 	return
@@ -90,8 +374,9 @@ func (apt *AptProvider) buildCommand(subCommand string, autoApprove bool, option
 	name = apt.Command
 	if apt.RequiresRoot == true {
 		name = "sudo"
+		args = append(args, apt.Command)
 	}
-	args = append(args, apt.Command, subCommand)
+	args = append(args, subCommand)
 
 	if autoApprove == true {
 		args = append(args, "-y")
@@ -104,58 +389,360 @@ func (apt *AptProvider) buildCommand(subCommand string, autoApprove bool, option
 	return
 }
 
-func (apt *AptProvider) installGPGKey(GPGKey string, packageName string) (keyPath string, err error) {
-	keyPath = "/etc/apt/keyrings/" + packageName + "-apt-keyring.gpg"
-	if _, err := os.Stat(keyPath); errors.Is(err, os.ErrNotExist) {
-		cmdCurl := exec.Command("curl", "-fsSL", GPGKey)
-		cmd := exec.Command("sudo", "gpg", "--dearmor", "-o", keyPath)
-		cmd.Stdin, _ = cmdCurl.StdoutPipe()
-		errBuffer := new(bytes.Buffer)
-		cmd.Stderr = errBuffer
-		err = cmd.Start()
-		_ = cmdCurl.Run()
-		err = cmd.Wait()
+const (
+	// aptSourcesDirectory holds the .sources files of the repositories.
+	aptSourcesDirectory = "/etc/apt/sources.list.d"
+	// aptPreferencesDirectory holds the preferences files pinning packages.
+	aptPreferencesDirectory = "/etc/apt/preferences.d"
+	// defaultPinPriority is the priority of a pin without one, the priority
+	// apt gives to the target release.
+	defaultPinPriority = 990
+	// aptHoldsRecord lists the packages held by pkgsmanager.
+	aptHoldsRecord = "/var/lib/pkgsmanager/apt-holds"
+	// aptKeyringsDirectory holds the keys downloaded for the repositories.
+	aptKeyringsDirectory = "/etc/apt/keyrings"
+	// managedFilePrefix starts the name of the files written for the
+	// repositories and the pins, so that the ones no longer referenced can
+	// be found.
+	managedFilePrefix = "pkgsmanager-"
+	// keyURLComment starts the comment of a .sources file recording the URL
+	// its key was downloaded from, so that it is downloaded again when the
+	// URL changes.
+	keyURLComment = "# Key downloaded from "
+)
+
+// SyncRepositories writes every repository to its deb822 .sources file when it
+// is missing or its content drifted from the configuration, then deletes the
+// files, and downloaded keys, of the repositories no package refers to anymore.
+func (apt *AptProvider) SyncRepositories(repositories []*models.Repository, referenced []string) (err error, cmdErr error) {
+	for _, repository := range repositories {
+		if err, cmdErr = apt.writeRepository(repository); err != nil {
+			return
+		}
+	}
+
+	sourcePaths, err := filepath.Glob(filepath.Join(aptSourcesDirectory, managedFilePrefix+"*.sources"))
+	if err != nil {
+		return
+	}
+	for _, sourcePath := range sourcePaths {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(sourcePath), managedFilePrefix), ".sources")
+		if slices.Contains(referenced, name) {
+			continue
+		}
+		if err, cmdErr = apt.removeFiles("Remove repository "+name, sourcePath, apt.keyringPath(name)); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (apt *AptProvider) writeRepository(repository *models.Repository) (err error, cmdErr error) {
+	sourcePath := filepath.Join(aptSourcesDirectory, managedFilePrefix+repository.Name+".sources")
+	current, readErr := os.ReadFile(sourcePath)
+
+	signedBy := repository.SignedBy
+	if strings.Contains(signedBy, "://") {
+		signedBy = apt.keyringPath(repository.Name)
+		urlChanged := readErr != nil || recordedKeyURL(string(current)) != repository.SignedBy
+		if err, cmdErr = apt.installGPGKey(repository.SignedBy, signedBy, repository.GPGFingerprint, repository.Name, urlChanged); err != nil {
+			return
+		}
+	} else if signedBy != "" && repository.GPGFingerprint != "" {
+		if err = apt.checkKeyring(signedBy, repository.GPGFingerprint, repository.Name); err != nil {
+			return
+		}
+	}
+
+	content := deb822Source(repository, signedBy)
+	if readErr != nil || string(current) != content {
+		var stderr string
+		stderr, err = apt.runCommand(PlannedStep{
+			Description: "Write repository " + repository.Name,
+			File:        sourcePath,
+			Content:     content,
+		}, "sudo", "tee", sourcePath)
+		if err != nil {
+			err = errors.New(fmt.Sprintf("Failed to write repository %s", repository.Name))
+			cmdErr = errors.New(stderr)
+			return
+		}
+	}
+
+	// Earlier versions wrote the sourceList of each package to its own .list
+	// file, which would declare the repository a second time.
+	for _, packageName := range repository.LegacyPackages {
+		if err, cmdErr = apt.removeFiles("Remove source list of "+packageName, filepath.Join(aptSourcesDirectory, packageName+".list")); err != nil {
+			return
+		}
 	}
+
 	return
 }
 
-func (apt *AptProvider) addSourceList(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
-	sourceListPath := "/etc/apt/sources.list.d/" + pkgConfiguration.Name + ".list"
-	if _, err = os.Stat(sourceListPath); errors.Is(err, os.ErrNotExist) {
-		sourceListSignature := ""
-		if pkgConfiguration.GPGKey != "" {
-			var keyPath string
-			keyPath, err = apt.installGPGKey(pkgConfiguration.GPGKey, pkgConfiguration.Name)
-			if err != nil {
-				return
-			}
-			sourceListSignature = "[arch=amd64 signed-by=" + keyPath + "]"
+// deb822Source renders the repository in the format of apt .sources files.
+func deb822Source(repository *models.Repository, signedBy string) string {
+	types := repository.Types
+	if len(types) == 0 {
+		types = models.StringList{"deb"}
+	}
+
+	var builder strings.Builder
+	builder.WriteString("# Written by pkgsmanager, changes are overwritten on sync.\n")
+	if strings.Contains(repository.SignedBy, "://") {
+		builder.WriteString(keyURLComment + repository.SignedBy + "\n")
+	}
+	for _, field := range []struct {
+		key    string
+		values []string
+	}{
+		{"Types", types},
+		{"URIs", repository.URIs},
+		{"Suites", repository.Suites},
+		{"Components", repository.Components},
+		{"Architectures", repository.Architectures},
+	} {
+		if len(field.values) > 0 {
+			builder.WriteString(field.key + ": " + strings.Join(field.values, " ") + "\n")
+		}
+	}
+	if signedBy != "" {
+		builder.WriteString("Signed-By: " + signedBy + "\n")
+	}
+
+	return builder.String()
+}
+
+// recordedKeyURL returns the URL the key of a .sources file written by
+// deb822Source was downloaded from, or an empty string.
+func recordedKeyURL(source string) string {
+	for _, line := range strings.Split(source, "\n") {
+		if keyURL, found := strings.CutPrefix(line, keyURLComment); found {
+			return keyURL
+		}
+	}
+
+	return ""
+}
+
+// removeFiles deletes the files that exist among paths.
+func (apt *AptProvider) removeFiles(description string, paths ...string) (err error, cmdErr error) {
+	var existing []string
+	for _, path := range paths {
+		if _, statErr := os.Stat(path); statErr == nil {
+			existing = append(existing, path)
 		}
+	}
+	if len(existing) == 0 {
+		return
+	}
+
+	stderr, err := apt.runCommand(PlannedStep{Description: description}, "sudo", append([]string{"rm", "-f"}, existing...)...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to delete %s", strings.Join(existing, ", ")))
+		cmdErr = errors.New(stderr)
+	}
 
-		sourceList := "deb " + sourceListSignature + " " + pkgConfiguration.SourceList
+	return
+}
 
-		cmd := exec.Command("sudo", "tee", "-a", sourceListPath)
-		cmd.Stdin = strings.NewReader(sourceList)
-		errBuffer := new(bytes.Buffer)
-		cmd.Stderr = errBuffer
-		err = cmd.Run()
+// SyncPreferences writes the preferences file of every pinned package when it
+// is missing or its content drifted from the configuration, then deletes the
+// preferences files written earlier for packages that are not in referenced.
+func (apt *AptProvider) SyncPreferences(packages []*models.PackageConfiguration, referenced []string) (err error, cmdErr error) {
+	for _, pkgConfiguration := range packages {
+		var content string
+		if content, err = preferencesContent(pkgConfiguration); err != nil {
+			return
+		}
+
+		preferencesPath := apt.preferencesPath(pkgConfiguration.Name)
+		if current, readErr := os.ReadFile(preferencesPath); readErr == nil && string(current) == content {
+			continue
+		}
+		var stderr string
+		stderr, err = apt.runCommand(PlannedStep{
+			Description: "Pin " + pkgConfiguration.Name,
+			File:        preferencesPath,
+			Content:     content,
+		}, "sudo", "tee", preferencesPath)
 		if err != nil {
-			cmdErr = errors.New(errBuffer.String())
+			err = errors.New(fmt.Sprintf("Failed to pin %s", pkgConfiguration.Name))
+			cmdErr = errors.New(stderr)
+			return
 		}
+	}
 
+	preferencesPaths, err := filepath.Glob(filepath.Join(aptPreferencesDirectory, managedFilePrefix+"*.pref"))
+	if err != nil {
+		return
 	}
+	for _, preferencesPath := range preferencesPaths {
+		if slices.ContainsFunc(referenced, func(packageName string) bool {
+			return apt.preferencesPath(packageName) == preferencesPath
+		}) {
+			continue
+		}
+		if err, cmdErr = apt.removeFiles("Remove pin "+filepath.Base(preferencesPath), preferencesPath); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// preferencesContent renders the apt preferences entry pinning the package.
+// Without a pin, the package is pinned to its target release or its version.
+func preferencesContent(pkgConfiguration *models.PackageConfiguration) (content string, err error) {
+	pin := pkgConfiguration.Apt.Pin
+	if pin == "" && pkgConfiguration.Apt.Release != "" {
+		pin = "release n=" + pkgConfiguration.Apt.Release
+	} else if pin == "" && pkgConfiguration.ConfiguredVersion != "" && pkgConfiguration.ConfiguredVersion != "latest" {
+		pin = "version " + pkgConfiguration.ConfiguredVersion
+	}
+	if pin == "" {
+		err = errors.New(fmt.Sprintf("The priority of %s needs a pin, a release or a version", pkgConfiguration.Name))
+		return
+	}
+
+	priority := pkgConfiguration.Apt.Priority
+	if priority == 0 {
+		priority = defaultPinPriority
+	}
+
+	content = fmt.Sprintf("# Written by pkgsmanager, changes are overwritten on sync.\nPackage: %s\nPin: %s\nPin-Priority: %d\n", pkgConfiguration.Name, pin, priority)
+
+	return
+}
+
+// preferencesPath returns the path of the preferences file of a package. apt
+// ignores the files whose name has other characters than letters, digits,
+// dashes, underscores and dots, such as the + of g++.
+func (apt *AptProvider) preferencesPath(packageName string) string {
+	return filepath.Join(aptPreferencesDirectory, managedFilePrefix+strings.ReplaceAll(packageName, "+", "_")+".pref")
+}
+
+func (apt *AptProvider) keyringPath(repositoryName string) string {
+	return filepath.Join(aptKeyringsDirectory, managedFilePrefix+repositoryName+".gpg")
+}
+
+// installGPGKey installs the key of the repository, downloaded from keyURL, as
+// a binary keyring at keyPath. An installed key is kept, unless urlChanged
+// tells it came from another URL or it lacks the expected fingerprint after a
+// key rotation: it is then downloaded again. A downloaded key with an expected
+// fingerprint is only installed when it holds no other key.
+func (apt *AptProvider) installGPGKey(keyURL string, keyPath string, expectedFingerprint string, repositoryName string, urlChanged bool) (err error, cmdErr error) {
+	installedKey, readErr := os.ReadFile(keyPath)
+	if readErr == nil && !urlChanged {
+		if expectedFingerprint == "" {
+			return
+		}
+		if found, _, _ := hasOnlyFingerprint(installedKey, expectedFingerprint); found {
+			return
+		}
+	} else if readErr != nil && !errors.Is(readErr, os.ErrNotExist) {
+		err = errors.New(fmt.Sprintf("Failed to read the keyring of repository %s: %s", repositoryName, readErr))
+		return
+	}
+
+	key, err := apt.download(keyURL)
+	if err != nil {
+		return
+	}
+	keyring, err := dearmor(key)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("The key of repository %s at %s is invalid: %s", repositoryName, keyURL, err))
+		return
+	}
+	if expectedFingerprint != "" {
+		found, fingerprints, checkErr := hasOnlyFingerprint(keyring, expectedFingerprint)
+		if checkErr != nil {
+			err = errors.New(fmt.Sprintf("The key of repository %s at %s is invalid: %s", repositoryName, keyURL, checkErr))
+			return
+		}
+		if !found {
+			err = errors.New(fmt.Sprintf("The key of repository %s at %s has fingerprint %s, expected only %s", repositoryName, keyURL, strings.Join(fingerprints, ", "), normalizeFingerprint(expectedFingerprint)))
+			return
+		}
+	} else if _, err = keyFingerprints(keyring); err != nil {
+		err = errors.New(fmt.Sprintf("The key of repository %s at %s is invalid: %s", repositoryName, keyURL, err))
+		return
+	}
+
+	description := "Install key of repository " + repositoryName
+	if readErr == nil && urlChanged {
+		description = "Replace key of repository " + repositoryName + ", its URL changed"
+	} else if readErr == nil {
+		description = "Replace rotated key of repository " + repositoryName
+	}
+	if err, cmdErr = apt.createDirectory(filepath.Dir(keyPath)); err != nil {
+		return
+	}
+
+	// The keyring is binary, it is fed to tee without being part of the step.
+	teeArgs := []string{"sudo", "tee", keyPath}
+	if apt.DryRun {
+		apt.recordStep(PlannedStep{Description: description, Command: teeArgs, File: keyPath, URL: keyURL})
+		return
+	}
+	_, stderr, runErr := apt.Executor.Run(Command{Name: teeArgs[0], Args: teeArgs[1:], Stdin: keyring})
+	if runErr != nil {
+		err = errors.New(fmt.Sprintf("Failed to install the key of repository %s", repositoryName))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+// createDirectory creates the directory when it is missing.
+func (apt *AptProvider) createDirectory(directory string) (err error, cmdErr error) {
+	if _, statErr := os.Stat(directory); statErr == nil {
+		return
+	}
+
+	stderr, err := apt.runCommand(PlannedStep{Description: "Create " + directory}, "sudo", "mkdir", "-p", "-m", "0755", directory)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to create %s", directory))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+// checkKeyring verifies that the keyring at keyPath, installed by other means,
+// holds no other key than the expected one.
+func (apt *AptProvider) checkKeyring(keyPath string, expectedFingerprint string, repositoryName string) (err error) {
+	key, err := os.ReadFile(keyPath)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to read the keyring of repository %s: %s", repositoryName, err))
+		return
+	}
+
+	found, fingerprints, err := hasOnlyFingerprint(key, expectedFingerprint)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("The keyring %s of repository %s is invalid: %s", keyPath, repositoryName, err))
+	} else if !found {
+		err = errors.New(fmt.Sprintf("The keyring %s of repository %s has fingerprint %s, expected only %s", keyPath, repositoryName, strings.Join(fingerprints, ", "), normalizeFingerprint(expectedFingerprint)))
+	}
+
 	return
 }
 
 func NewAptProvider() *AptProvider {
 	return &AptProvider{
-		&AbstractProvider{
+		AbstractProvider: &AbstractProvider{
 			Command:          "apt-get",
 			InstallCommand:   "install",
+			RemoveCommand:    "remove",
 			UpdateCommand:    "update",
+			UpgradeCommand:   "install",
 			CleanCommand:     "clean",
 			RequiresRoot:     true,
+			Lock:             "dpkg",
+			Executor:         NewExecExecutor(),
 			VersionSeparator: "=",
 		},
+		HoldsRecord: aptHoldsRecord,
 	}
 }
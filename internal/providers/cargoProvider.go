@@ -0,0 +1,178 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"qrobcis/pkgsmanager/internal/models"
+	"strings"
+)
+
+type CargoProvider struct {
+	*AbstractProvider
+}
+
+// InstallPackage runs cargo install. The version pins the crate version for
+// crates.io installs and selects the tag for git installs, latest selecting
+// the default branch.
+func (cargo *CargoProvider) InstallPackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	var installArgs []string
+	if pkgConfiguration.Cargo.Git != "" {
+		installArgs = append(installArgs, "--git", pkgConfiguration.Cargo.Git)
+		if pkgConfiguration.Version != "" && pkgConfiguration.Version != "latest" {
+			installArgs = append(installArgs, "--tag", pkgConfiguration.Version)
+		}
+	} else if pkgConfiguration.Version != "" && pkgConfiguration.Version != "latest" {
+		installArgs = append(installArgs, "--version", pkgConfiguration.Version)
+	}
+
+	if pkgConfiguration.Cargo.Locked {
+		installArgs = append(installArgs, "--locked")
+	}
+	if len(pkgConfiguration.Cargo.Features) > 0 {
+		installArgs = append(installArgs, "--features", strings.Join(pkgConfiguration.Cargo.Features, ","))
+	}
+	installArgs = append(installArgs, pkgConfiguration.Name)
+
+	name, args := cargo.buildCommand(cargo.InstallCommand, installArgs...)
+	stderr, err := cargo.runCommand(PlannedStep{Description: "Install " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to install %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (cargo *CargoProvider) RemovePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	name, args := cargo.buildCommand(cargo.RemoveCommand, pkgConfiguration.Name)
+	stderr, err := cargo.runCommand(PlannedStep{Description: "Remove " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to remove %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+// UpgradePackage runs cargo install again without a version, which replaces
+// the installed crate when a newer version or git commit is available.
+func (cargo *CargoProvider) UpgradePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	var upgradeArgs []string
+	if pkgConfiguration.Cargo.Git != "" {
+		upgradeArgs = append(upgradeArgs, "--git", pkgConfiguration.Cargo.Git)
+	}
+	if pkgConfiguration.Cargo.Locked {
+		upgradeArgs = append(upgradeArgs, "--locked")
+	}
+	if len(pkgConfiguration.Cargo.Features) > 0 {
+		upgradeArgs = append(upgradeArgs, "--features", strings.Join(pkgConfiguration.Cargo.Features, ","))
+	}
+	upgradeArgs = append(upgradeArgs, pkgConfiguration.Name)
+
+	name, args := cargo.buildCommand(cargo.UpgradeCommand, upgradeArgs...)
+	stderr, err := cargo.runCommand(PlannedStep{Description: "Upgrade " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to upgrade %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (cargo *CargoProvider) UpdateRegistry() (err error, cmdErr error) {
+	return
+}
+
+func (cargo *CargoProvider) CleanRegistry() (err error, cmdErr error) {
+	return
+}
+
+func (cargo *CargoProvider) IsInstalled(pkgConfiguration *models.PackageConfiguration) (installed bool, err error) {
+	version, err := cargo.InstalledVersion(pkgConfiguration)
+	installed = version != ""
+
+	return
+}
+
+// CanPinVersion is false for git installs: cargo install --list reports the
+// crate version of the commit, which is not a tag that can be installed.
+func (cargo *CargoProvider) CanPinVersion(pkgConfiguration *models.PackageConfiguration) bool {
+	return pkgConfiguration.Cargo.Git == ""
+}
+
+// InstalledVersion parses the "name v1.2.3 (source):" headers printed by
+// cargo install --list, each followed by the indented list of binaries.
+func (cargo *CargoProvider) InstalledVersion(pkgConfiguration *models.PackageConfiguration) (version string, err error) {
+	name, args := cargo.buildCommand(cargo.InstallCommand, "--list")
+	stdout, err := cargo.queryCommand(name, args...)
+	if err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(string(stdout), "\n") {
+		if line == "" || strings.HasPrefix(line, " ") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimSuffix(line, ":"))
+		if len(fields) >= 2 && fields[0] == pkgConfiguration.Name {
+			version = strings.TrimPrefix(strings.TrimSuffix(fields[1], ":"), "v")
+			break
+		}
+	}
+
+	return
+}
+
+func (cargo *CargoProvider) buildCommand(subCommand string, options ...string) (name string, args []string) {
+	name = cargo.Command
+	if cargo.RequiresRoot == true {
+		name = "sudo"
+		args = append(args, cargo.Command)
+	}
+
+	args = append(args, subCommand)
+
+	if len(options) > 0 {
+		args = append(args, options...)
+	}
+
+	return
+}
+
+func NewCargoProvider() *CargoProvider {
+	return &CargoProvider{
+		&AbstractProvider{
+			Command:          "cargo",
+			InstallCommand:   "install",
+			RemoveCommand:    "uninstall",
+			UpdateCommand:    "",
+			UpgradeCommand:   "install",
+			CleanCommand:     "",
+			RequiresRoot:     false,
+			Executor:         NewExecExecutor(),
+			VersionSeparator: "@",
+		},
+	}
+}
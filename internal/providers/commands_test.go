@@ -0,0 +1,481 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package providers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"qrobcis/pkgsmanager/internal/models"
+	"qrobcis/pkgsmanager/internal/types/provider"
+	"qrobcis/pkgsmanager/internal/types/state"
+	"slices"
+	"testing"
+)
+
+func TestProviderCommands(t *testing.T) {
+	noHold := false
+	// The sudo handling is checked by flipping RequiresRoot.
+	userApt := NewAptProvider()
+	userApt.RequiresRoot = false
+	sudoNpm := NewNpmProvider()
+	sudoNpm.RequiresRoot = true
+	userGem := NewGemProvider()
+	userGem.RequiresRoot = false
+	// The deb provider records installed checksums in its cache directory.
+	cachedDeb := NewDebProvider()
+	cachedDeb.CacheDirectory = t.TempDir()
+
+	tests := []struct {
+		name        string
+		provider    PackageProvider
+		pkg         models.PackageConfiguration
+		outputs     map[string]RecordedOutput
+		wantInstall []string
+		wantRemove  []string
+	}{
+		{
+			name:        "apt",
+			provider:    NewAptProvider(),
+			pkg:         models.PackageConfiguration{Name: "curl"},
+			wantInstall: []string{"sudo apt-get install -y curl"},
+			wantRemove:  []string{"sudo apt-get remove -y --allow-change-held-packages curl"},
+		},
+		{
+			name:        "apt pinned to a release and a version",
+			provider:    NewAptProvider(),
+			pkg:         models.PackageConfiguration{Name: "curl", Version: "7.88", ConfiguredVersion: "7.88", Apt: models.AptOptions{Release: "bookworm-backports"}},
+			wantInstall: []string{"sudo apt-get install -y -t bookworm-backports --allow-change-held-packages curl=7.88"},
+			wantRemove:  []string{"sudo apt-get remove -y --allow-change-held-packages curl"},
+		},
+		{
+			name:        "apt version from the lockfile",
+			provider:    NewAptProvider(),
+			pkg:         models.PackageConfiguration{Name: "curl", Version: "7.88"},
+			wantInstall: []string{"sudo apt-get install -y curl=7.88"},
+			wantRemove:  []string{"sudo apt-get remove -y --allow-change-held-packages curl"},
+		},
+		{
+			name:        "apt purged",
+			provider:    NewAptProvider(),
+			pkg:         models.PackageConfiguration{Name: "curl", Version: "latest", Apt: models.AptOptions{Hold: &noHold}, State: state.Purged},
+			wantInstall: []string{"sudo apt-get install -y --allow-change-held-packages curl"},
+			wantRemove:  []string{"sudo apt-get purge -y --allow-change-held-packages curl"},
+		},
+		{
+			name:        "apt without sudo",
+			provider:    userApt,
+			pkg:         models.PackageConfiguration{Name: "curl"},
+			wantInstall: []string{"apt-get install -y curl"},
+			wantRemove:  []string{"apt-get remove -y --allow-change-held-packages curl"},
+		},
+		{
+			name:        "npm",
+			provider:    NewNpmProvider(),
+			pkg:         models.PackageConfiguration{Name: "typescript", Version: "5.0.0"},
+			wantInstall: []string{"npm install -g typescript@5.0.0"},
+			wantRemove:  []string{"npm uninstall -g typescript"},
+		},
+		{
+			name:        "npm with sudo",
+			provider:    sudoNpm,
+			pkg:         models.PackageConfiguration{Name: "typescript"},
+			wantInstall: []string{"sudo npm install -g typescript"},
+			wantRemove:  []string{"sudo npm uninstall -g typescript"},
+		},
+		{
+			name:        "gem",
+			provider:    NewGemProvider(),
+			pkg:         models.PackageConfiguration{Name: "rails", Version: "7.0"},
+			wantInstall: []string{"sudo gem install rails -v 7.0"},
+			wantRemove:  []string{"sudo gem uninstall rails -x -v 7.0"},
+		},
+		{
+			name:        "gem without sudo",
+			provider:    userGem,
+			pkg:         models.PackageConfiguration{Name: "rails"},
+			wantInstall: []string{"gem install rails"},
+			wantRemove:  []string{"gem uninstall rails -x -a"},
+		},
+		{
+			name:        "go",
+			provider:    NewGoProvider(),
+			pkg:         models.PackageConfiguration{Name: "golang.org/x/tools/gopls", Version: "v0.15.0"},
+			wantInstall: []string{"go install golang.org/x/tools/gopls@v0.15.0"},
+			wantRemove:  []string{"go env GOBIN GOPATH"},
+		},
+		{
+			name:        "snap",
+			provider:    NewSnapProvider(),
+			pkg:         models.PackageConfiguration{Name: "code", Version: "stable", Provider: provider.Snap},
+			wantInstall: []string{"sudo snap install code --classic --stable"},
+			wantRemove:  []string{"sudo snap remove code"},
+		},
+		{
+			name:        "pip user",
+			provider:    NewPipProvider(),
+			pkg:         models.PackageConfiguration{Name: "black", Version: "24.1"},
+			wantInstall: []string{"python3 -m pip install --user black==24.1"},
+			wantRemove:  []string{"python3 -m pip uninstall -y black"},
+		},
+		{
+			name:        "pipx",
+			provider:    NewPipProvider(),
+			pkg:         models.PackageConfiguration{Name: "black", Pip: models.PipOptions{Mode: "pipx"}},
+			wantInstall: []string{"pipx install --force black"},
+			wantRemove:  []string{"pipx uninstall black"},
+		},
+		{
+			name:        "pip venv",
+			provider:    NewPipProvider(),
+			pkg:         models.PackageConfiguration{Name: "black", Pip: models.PipOptions{Mode: "venv", Venv: "/opt/venv"}},
+			wantInstall: []string{"python3 -m venv /opt/venv", "/opt/venv/bin/python -m pip install black"},
+			wantRemove:  []string{"/opt/venv/bin/python -m pip uninstall -y black"},
+		},
+		{
+			name:        "cargo",
+			provider:    NewCargoProvider(),
+			pkg:         models.PackageConfiguration{Name: "ripgrep", Version: "14.0.0", Cargo: models.CargoOptions{Locked: true, Features: []string{"pcre2"}}},
+			wantInstall: []string{"cargo install --version 14.0.0 --locked --features pcre2 ripgrep"},
+			wantRemove:  []string{"cargo uninstall ripgrep"},
+		},
+		{
+			name:        "cargo git",
+			provider:    NewCargoProvider(),
+			pkg:         models.PackageConfiguration{Name: "tool", Version: "v1.0", Cargo: models.CargoOptions{Git: "https://example.org/tool.git"}},
+			wantInstall: []string{"cargo install --git https://example.org/tool.git --tag v1.0 tool"},
+			wantRemove:  []string{"cargo uninstall tool"},
+		},
+		{
+			name:        "cargo git default branch",
+			provider:    NewCargoProvider(),
+			pkg:         models.PackageConfiguration{Name: "tool", Version: "latest", Cargo: models.CargoOptions{Git: "https://example.org/tool.git"}},
+			wantInstall: []string{"cargo install --git https://example.org/tool.git tool"},
+			wantRemove:  []string{"cargo uninstall tool"},
+		},
+		{
+			name:        "flatpak user",
+			provider:    NewFlatpakProvider(),
+			pkg:         models.PackageConfiguration{Name: "org.gimp.GIMP", Version: "stable"},
+			wantInstall: []string{"flatpak install --user -y --noninteractive flathub org.gimp.GIMP//stable"},
+			wantRemove:  []string{"flatpak uninstall --user -y --noninteractive org.gimp.GIMP"},
+		},
+		{
+			name:     "flatpak system with a remote",
+			provider: NewFlatpakProvider(),
+			pkg:      models.PackageConfiguration{Name: "org.gimp.GIMP", Flatpak: models.FlatpakOptions{Scope: "system", Remote: "fh", RemoteURL: "https://example.org/fh.flatpakrepo"}},
+			wantInstall: []string{
+				"flatpak remotes --system --columns=name",
+				"sudo flatpak remote-add --system --if-not-exists fh https://example.org/fh.flatpakrepo",
+				"sudo flatpak install --system -y --noninteractive fh org.gimp.GIMP",
+			},
+			wantRemove: []string{"sudo flatpak uninstall --system -y --noninteractive org.gimp.GIMP"},
+		},
+		{
+			name:        "flatpak remote already added",
+			provider:    NewFlatpakProvider(),
+			pkg:         models.PackageConfiguration{Name: "org.gimp.GIMP", Flatpak: models.FlatpakOptions{Remote: "fh", RemoteURL: "https://example.org/fh.flatpakrepo"}},
+			outputs:     map[string]RecordedOutput{"flatpak remotes --user --columns=name": {Stdout: "flathub\nfh\n"}},
+			wantInstall: []string{"flatpak remotes --user --columns=name", "flatpak install --user -y --noninteractive fh org.gimp.GIMP"},
+			wantRemove:  []string{"flatpak uninstall --user -y --noninteractive org.gimp.GIMP"},
+		},
+		{
+			name:        "dnf",
+			provider:    NewDnfProvider(),
+			pkg:         models.PackageConfiguration{Name: "curl", Version: "8.0"},
+			wantInstall: []string{"sudo dnf install -y curl-8.0"},
+			wantRemove:  []string{"sudo dnf remove -y curl"},
+		},
+		{
+			name:        "pacman",
+			provider:    NewPacmanProvider(),
+			pkg:         models.PackageConfiguration{Name: "curl"},
+			wantInstall: []string{"sudo pacman -S --noconfirm --needed curl"},
+			wantRemove:  []string{"sudo pacman -Rns --noconfirm curl"},
+		},
+		{
+			name:        "zypper",
+			provider:    NewZypperProvider(),
+			pkg:         models.PackageConfiguration{Name: "curl", Version: "8.0"},
+			wantInstall: []string{"sudo zypper --non-interactive install curl=8.0"},
+			wantRemove:  []string{"sudo zypper --non-interactive remove curl"},
+		},
+		{
+			name:        "apk",
+			provider:    NewApkProvider(),
+			pkg:         models.PackageConfiguration{Name: "curl", Version: "8.0"},
+			wantInstall: []string{"sudo apk add curl=8.0"},
+			wantRemove:  []string{"sudo apk del curl"},
+		},
+		{
+			name:     "deb",
+			provider: cachedDeb,
+			pkg:      models.PackageConfiguration{Name: "hello", Deb: models.DebOptions{URL: "/srv/hello.deb"}},
+			outputs:  map[string]RecordedOutput{"dpkg-deb --show --showformat=${Package}|${Version} /srv/hello.deb": {Stdout: "hello|1.0"}},
+			wantInstall: []string{
+				"dpkg-deb --show --showformat=${Package}|${Version} /srv/hello.deb",
+				"sudo apt-get install -y /srv/hello.deb",
+			},
+			wantRemove: []string{"sudo apt-get remove -y hello"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			executor := NewRecordingExecutor()
+			for commandLine, output := range test.outputs {
+				executor.Outputs[commandLine] = output
+			}
+			test.provider.SetExecutor(executor)
+			if apt, ok := test.provider.(*AptProvider); ok {
+				apt.HoldsRecord = filepath.Join(t.TempDir(), "apt-holds")
+			}
+
+			if err, cmdErr := test.provider.InstallPackage(&test.pkg); err != nil {
+				t.Fatalf("InstallPackage() error = %v, %v", err, cmdErr)
+			}
+			if commandLines := executor.CommandLines(); !slices.Equal(commandLines, test.wantInstall) {
+				t.Fatalf("InstallPackage() ran %q, want %q", commandLines, test.wantInstall)
+			}
+
+			executor.Commands = nil
+			if err, cmdErr := test.provider.RemovePackage(&test.pkg); err != nil {
+				t.Fatalf("RemovePackage() error = %v, %v", err, cmdErr)
+			}
+			if commandLines := executor.CommandLines(); !slices.Equal(commandLines, test.wantRemove) {
+				t.Fatalf("RemovePackage() ran %q, want %q", commandLines, test.wantRemove)
+			}
+		})
+	}
+}
+
+func TestProviderCommandFailure(t *testing.T) {
+	executor := NewRecordingExecutor()
+	executor.Outputs["sudo apt-get install -y curl"] = RecordedOutput{Stderr: "E: Unable to locate package curl", Err: &ExitError{Command: "sudo", ExitCode: 100}}
+	apt := NewAptProvider()
+	apt.HoldsRecord = filepath.Join(t.TempDir(), "apt-holds")
+	apt.SetExecutor(executor)
+
+	err, cmdErr := apt.InstallPackage(&models.PackageConfiguration{Name: "curl"})
+	if err == nil || cmdErr == nil || cmdErr.Error() != "E: Unable to locate package curl" {
+		t.Fatalf("InstallPackage() error = %v, %v, want the stderr of apt-get", err, cmdErr)
+	}
+}
+
+func TestDryRunRecordsSteps(t *testing.T) {
+	executor := NewRecordingExecutor()
+	npm := NewNpmProvider()
+	npm.SetExecutor(executor)
+	npm.SetDryRun(true)
+
+	if err, _ := npm.InstallPackage(&models.PackageConfiguration{Name: "typescript"}); err != nil {
+		t.Fatalf("InstallPackage() error = %v", err)
+	}
+	if commandLines := executor.CommandLines(); len(commandLines) > 0 {
+		t.Fatalf("InstallPackage() ran %q in dry-run mode", commandLines)
+	}
+	plan := npm.TakePlan()
+	if len(plan) != 1 || !slices.Equal(plan[0].Command, []string{"npm", "install", "-g", "typescript"}) {
+		t.Fatalf("TakePlan() = %+v, want the npm install command", plan)
+	}
+}
+
+func TestPacmanUpgradesTheSystemOnlyWhenAsked(t *testing.T) {
+	executor := NewRecordingExecutor()
+	pacman := NewPacmanProvider()
+	pacman.SetExecutor(executor)
+
+	if err, _ := pacman.UpdateRegistry(); err != nil {
+		t.Fatalf("UpdateRegistry() error = %v", err)
+	}
+	if err, _ := pacman.UpgradePackage(&models.PackageConfiguration{Name: "curl"}); err != nil {
+		t.Fatalf("UpgradePackage() error = %v", err)
+	}
+	want := []string{"sudo pacman -Sy", "sudo pacman -S --noconfirm --needed curl"}
+	if commandLines := executor.CommandLines(); !slices.Equal(commandLines, want) {
+		t.Fatalf("UpdateRegistry() and UpgradePackage() ran %q, want %q", commandLines, want)
+	}
+
+	executor.Commands = nil
+	if err, _ := pacman.UpgradeSystem(); err != nil {
+		t.Fatalf("UpgradeSystem() error = %v", err)
+	}
+	want = []string{"sudo pacman -Syu --noconfirm"}
+	if commandLines := executor.CommandLines(); !slices.Equal(commandLines, want) {
+		t.Fatalf("UpgradeSystem() ran %q, want %q", commandLines, want)
+	}
+}
+
+func TestPipMissingCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		missing []string
+		pkg     models.PackageConfiguration
+		want    string
+	}{
+		{
+			name: "user site",
+			pkg:  models.PackageConfiguration{Name: "black"},
+		},
+		{
+			name:    "user site without python3",
+			missing: []string{"python3"},
+			pkg:     models.PackageConfiguration{Name: "black"},
+			want:    "python3",
+		},
+		{
+			name:    "pipx without python3",
+			missing: []string{"python3"},
+			pkg:     models.PackageConfiguration{Name: "black", Pip: models.PipOptions{Mode: "pipx"}},
+		},
+		{
+			name:    "pipx not installed",
+			missing: []string{"pipx"},
+			pkg:     models.PackageConfiguration{Name: "black", Pip: models.PipOptions{Mode: "pipx"}},
+			want:    "pipx",
+		},
+		{
+			name:    "custom interpreter not installed",
+			missing: []string{"python3.12"},
+			pkg:     models.PackageConfiguration{Name: "black", Pip: models.PipOptions{Interpreter: "python3.12"}},
+			want:    "python3.12",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			executor := NewRecordingExecutor()
+			for _, command := range test.missing {
+				executor.Missing[command] = true
+			}
+			pip := NewPipProvider()
+			pip.SetExecutor(executor)
+
+			if !pip.IsAvailable() {
+				t.Fatalf("IsAvailable() = false with %q missing", test.missing)
+			}
+			if command := pip.MissingCommand(&test.pkg); command != test.want {
+				t.Fatalf("MissingCommand() = %q, want %q", command, test.want)
+			}
+		})
+	}
+}
+
+func TestAptPurgesConfigFiles(t *testing.T) {
+	executor := NewRecordingExecutor()
+	executor.Outputs["dpkg-query -W -f=${db:Status-Abbrev}|${Version} curl"] = RecordedOutput{Stdout: "rc |7.88"}
+	apt := NewAptProvider()
+	apt.SetExecutor(executor)
+
+	tests := []struct {
+		state state.State
+		want  bool
+	}{
+		{state: state.Present, want: false},
+		{state: state.Absent, want: false},
+		{state: state.Purged, want: true},
+	}
+	for _, test := range tests {
+		installed, err := apt.IsInstalled(&models.PackageConfiguration{Name: "curl", State: test.state})
+		if err != nil || installed != test.want {
+			t.Fatalf("IsInstalled() with state %s = %v, %v, want %v", test.state, installed, err, test.want)
+		}
+	}
+	if version, err := apt.InstalledVersion(&models.PackageConfiguration{Name: "curl", State: state.Purged}); err != nil || version != "" {
+		t.Fatalf("InstalledVersion() = %q, %v, want no version", version, err)
+	}
+}
+
+func TestPinsVersion(t *testing.T) {
+	commit := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		name     string
+		provider PackageProvider
+		version  string
+		want     bool
+	}{
+		{name: "npm latest", provider: NewNpmProvider(), version: "latest", want: false},
+		{name: "npm version", provider: NewNpmProvider(), version: "5.0.0", want: true},
+		{name: "snap channel", provider: NewSnapProvider(), version: "stable", want: false},
+		{name: "flatpak branch", provider: NewFlatpakProvider(), version: "stable", want: false},
+		{name: "flatpak commit", provider: NewFlatpakProvider(), version: commit, want: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pkgConfiguration := &models.PackageConfiguration{Name: "package", Version: test.version, ConfiguredVersion: test.version}
+			if pinned := test.provider.PinsVersion(pkgConfiguration); pinned != test.want {
+				t.Fatalf("PinsVersion() = %v, want %v", pinned, test.want)
+			}
+		})
+	}
+}
+
+func TestDebUpgradesOnlyANewFile(t *testing.T) {
+	release := "release 1"
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		downloads += 1
+		_, _ = writer.Write([]byte(release))
+	}))
+	defer server.Close()
+
+	cacheDirectory := t.TempDir()
+	filePath := filepath.Join(cacheDirectory, "hello", "hello.deb")
+	executor := NewRecordingExecutor()
+	executor.Outputs["dpkg-deb --show --showformat=${Package}|${Version} "+filePath] = RecordedOutput{Stdout: "hello|1.0"}
+	deb := NewDebProvider()
+	deb.CacheDirectory = cacheDirectory
+	deb.HTTPClient = server.Client()
+	deb.SetExecutor(executor)
+
+	checksum := func(content string) string {
+		sum := sha256.Sum256([]byte(content))
+		return hex.EncodeToString(sum[:])
+	}
+	pkgConfiguration := &models.PackageConfiguration{Name: "hello", Deb: models.DebOptions{URL: server.URL + "/hello.deb", SHA256: checksum(release)}}
+	install := []string{"dpkg-deb --show --showformat=${Package}|${Version} " + filePath, "sudo apt-get install -y " + filePath}
+
+	if err, cmdErr := deb.InstallPackage(pkgConfiguration); err != nil {
+		t.Fatalf("InstallPackage() error = %v, %v", err, cmdErr)
+	}
+	if commandLines := executor.CommandLines(); downloads != 1 || !slices.Equal(commandLines, install) {
+		t.Fatalf("InstallPackage() downloaded %d time(s) and ran %q, want 1 download and %q", downloads, commandLines, install)
+	}
+
+	executor.Commands = nil
+	if err, cmdErr := deb.UpgradePackage(pkgConfiguration); err != nil {
+		t.Fatalf("UpgradePackage() error = %v, %v", err, cmdErr)
+	}
+	if commandLines := executor.CommandLines(); downloads != 1 || len(commandLines) > 0 {
+		t.Fatalf("UpgradePackage() of the same file downloaded %d time(s) and ran %q", downloads-1, commandLines)
+	}
+
+	release = "release 2"
+	pkgConfiguration.Deb.SHA256 = checksum(release)
+	if err, cmdErr := deb.UpgradePackage(pkgConfiguration); err != nil {
+		t.Fatalf("UpgradePackage() error = %v, %v", err, cmdErr)
+	}
+	if commandLines := executor.CommandLines(); downloads != 2 || !slices.Equal(commandLines, install) {
+		t.Fatalf("UpgradePackage() of a new file downloaded %d time(s) and ran %q, want %q", downloads-1, commandLines, install)
+	}
+}
@@ -0,0 +1,368 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package providers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"qrobcis/pkgsmanager/internal/models"
+	"qrobcis/pkgsmanager/internal/types/state"
+	"strings"
+)
+
+// DebProvider installs .deb files, downloaded from a URL or found on the
+// machine, with apt-get so that their dependencies are installed too.
+type DebProvider struct {
+	*AbstractProvider
+	// CacheDirectory holds the downloaded files, in the user cache directory
+	// when it is empty.
+	CacheDirectory string
+}
+
+// InstallPackage installs the .deb file of the package, after checking it is
+// the requested package and version.
+func (deb *DebProvider) InstallPackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	return deb.installFile(pkgConfiguration)
+}
+
+func (deb *DebProvider) RemovePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	subCommand := deb.RemoveCommand
+	if pkgConfiguration.State == state.Purged {
+		subCommand = "purge"
+	}
+
+	name, args := deb.buildCommand(subCommand, pkgConfiguration.Name)
+	stderr, err := deb.runCommand(PlannedStep{Description: "Remove " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to remove %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+		return
+	}
+	if checksumPath, pathErr := deb.installedChecksumPath(pkgConfiguration); pathErr == nil && !deb.DryRun {
+		_ = os.Remove(checksumPath)
+	}
+
+	return
+}
+
+// UpgradePackage installs the file of the package when its configured sha256
+// differs from the one of the installed file, a new release being published
+// along with its checksum. Nothing is downloaded when the file is the same.
+func (deb *DebProvider) UpgradePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	if pkgConfiguration.Deb.SHA256 != "" && strings.EqualFold(deb.installedChecksum(pkgConfiguration), pkgConfiguration.Deb.SHA256) {
+		return
+	}
+
+	return deb.installFile(pkgConfiguration)
+}
+
+func (deb *DebProvider) UpdateRegistry() (err error, cmdErr error) {
+	return
+}
+
+func (deb *DebProvider) CleanRegistry() (err error, cmdErr error) {
+	return
+}
+
+func (deb *DebProvider) IsInstalled(pkgConfiguration *models.PackageConfiguration) (installed bool, err error) {
+	version, err := deb.InstalledVersion(pkgConfiguration)
+	installed = version != ""
+
+	return
+}
+
+func (deb *DebProvider) InstalledVersion(pkgConfiguration *models.PackageConfiguration) (version string, err error) {
+	return dpkgInstalledVersion(deb.AbstractProvider, pkgConfiguration.Name)
+}
+
+// WantedVersion returns the version of the .deb file of the package, so that
+// the package is only installed when dpkg has another version. A file given
+// by URL is never downloaded here: its version is unknown until sync caches
+// it.
+func (deb *DebProvider) WantedVersion(pkgConfiguration *models.PackageConfiguration) (version string, err error) {
+	filePath, found, err := deb.localFile(pkgConfiguration)
+	if err != nil {
+		return
+	}
+	if !found {
+		err = fmt.Errorf("%s is %w", pkgConfiguration.Deb.URL, ErrNotDownloaded)
+		return
+	}
+
+	return deb.fileVersion(pkgConfiguration, filePath)
+}
+
+// CanPinVersion is false: the version is the one of the file.
+func (deb *DebProvider) CanPinVersion(pkgConfiguration *models.PackageConfiguration) bool {
+	return false
+}
+
+// installFile installs the .deb file of the package, downloading it first
+// when it is not cached. In dry-run mode the download is only planned, and the
+// file is checked once downloaded. The checksum of the installed file is
+// recorded for UpgradePackage.
+func (deb *DebProvider) installFile(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	filePath, found, err := deb.localFile(pkgConfiguration)
+	if err != nil {
+		return
+	}
+
+	location := pkgConfiguration.Deb.URL
+	if isURL(location) && !found {
+		if deb.DryRun {
+			deb.recordStep(PlannedStep{Description: "Download " + filepath.Base(filePath), File: filePath, URL: location})
+		} else if err = deb.downloadFile(pkgConfiguration, filePath); err != nil {
+			return
+		}
+	}
+	if !deb.DryRun || found {
+		if _, err = deb.fileVersion(pkgConfiguration, filePath); err != nil {
+			return
+		}
+	}
+
+	name, args := deb.buildCommand(deb.InstallCommand, filePath)
+	stderr, err := deb.runCommand(PlannedStep{Description: "Install " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to install %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+		return
+	}
+	if pkgConfiguration.Deb.SHA256 != "" && !deb.DryRun {
+		err = deb.recordChecksum(pkgConfiguration)
+	}
+
+	return
+}
+
+// installedChecksum returns the sha256 of the file the package was last
+// installed from, or an empty string when it is not known.
+func (deb *DebProvider) installedChecksum(pkgConfiguration *models.PackageConfiguration) string {
+	checksumPath, err := deb.installedChecksumPath(pkgConfiguration)
+	if err != nil {
+		return ""
+	}
+	content, err := os.ReadFile(checksumPath)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(content))
+}
+
+func (deb *DebProvider) recordChecksum(pkgConfiguration *models.PackageConfiguration) (err error) {
+	checksumPath, err := deb.installedChecksumPath(pkgConfiguration)
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(checksumPath), 0o755); err != nil {
+		return
+	}
+
+	return os.WriteFile(checksumPath, []byte(pkgConfiguration.Deb.SHA256+"\n"), 0o644)
+}
+
+// installedChecksumPath returns where the sha256 of the installed file of the
+// package is recorded, next to its cached files.
+func (deb *DebProvider) installedChecksumPath(pkgConfiguration *models.PackageConfiguration) (checksumPath string, err error) {
+	cacheDirectory, err := deb.cacheDirectory()
+	if err != nil {
+		return
+	}
+
+	return filepath.Join(cacheDirectory, pkgConfiguration.Name, "installed.sha256"), nil
+}
+
+// localFile returns the absolute path of the .deb file of the package, on the
+// machine or in the cache for a file given by URL. found tells whether a file
+// given by URL is cached with the configured checksum; a path on the machine
+// is always found, and its checksum is checked.
+func (deb *DebProvider) localFile(pkgConfiguration *models.PackageConfiguration) (filePath string, found bool, err error) {
+	location := pkgConfiguration.Deb.URL
+	if location == "" {
+		err = errors.New(fmt.Sprintf("Package %s has no deb url", pkgConfiguration.Name))
+		return
+	}
+
+	if !isURL(location) {
+		if filePath, err = filepath.Abs(expandHome(location)); err != nil {
+			return
+		}
+		found, err = true, checkSHA256(pkgConfiguration, filePath)
+		return
+	}
+
+	if pkgConfiguration.Deb.SHA256 == "" {
+		err = errors.New(fmt.Sprintf("Package %s is downloaded from %s without a deb sha256 to check it against", pkgConfiguration.Name, location))
+		return
+	}
+	if filePath, err = deb.cachePath(pkgConfiguration, location); err != nil {
+		return
+	}
+	if _, statErr := os.Stat(filePath); statErr == nil {
+		found = checkSHA256(pkgConfiguration, filePath) == nil
+	}
+
+	return
+}
+
+// downloadFile downloads the .deb file of the package to filePath, in the
+// cache. The file is renamed once complete and checked, a cached file is never
+// partial.
+func (deb *DebProvider) downloadFile(pkgConfiguration *models.PackageConfiguration, filePath string) (err error) {
+	content, err := deb.download(pkgConfiguration.Deb.URL)
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return
+	}
+	temporaryPath := filePath + ".part"
+	if err = os.WriteFile(temporaryPath, content, 0o644); err != nil {
+		return
+	}
+	if err = checkSHA256(pkgConfiguration, temporaryPath); err != nil {
+		_ = os.Remove(temporaryPath)
+		return
+	}
+
+	return os.Rename(temporaryPath, filePath)
+}
+
+// isURL tells whether the location of a .deb file is a URL rather than a path.
+func isURL(location string) bool {
+	return strings.Contains(location, "://")
+}
+
+// cachePath returns where the file downloaded from location is cached, named
+// after the last element of its path.
+func (deb *DebProvider) cachePath(pkgConfiguration *models.PackageConfiguration, location string) (filePath string, err error) {
+	cacheDirectory, err := deb.cacheDirectory()
+	if err != nil {
+		return
+	}
+
+	parsedURL, err := url.Parse(location)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Invalid deb url %s: %s", location, err))
+		return
+	}
+	fileName := path.Base(parsedURL.Path)
+	if !strings.HasSuffix(fileName, ".deb") {
+		fileName = pkgConfiguration.Name + ".deb"
+	}
+	filePath = filepath.Join(cacheDirectory, pkgConfiguration.Name, fileName)
+
+	return
+}
+
+// cacheDirectory returns CacheDirectory, or its default in the user cache
+// directory.
+func (deb *DebProvider) cacheDirectory() (cacheDirectory string, err error) {
+	if deb.CacheDirectory != "" {
+		return deb.CacheDirectory, nil
+	}
+	userCache, err := os.UserCacheDir()
+	if err != nil {
+		return
+	}
+
+	return filepath.Join(userCache, "pkgsmanager", "deb"), nil
+}
+
+// fileVersion reads the version of the .deb file, checking that it is the one
+// of the package and the requested version, if any.
+func (deb *DebProvider) fileVersion(pkgConfiguration *models.PackageConfiguration, filePath string) (version string, err error) {
+	stdout, err := deb.queryCommand("dpkg-deb", "--show", "--showformat=${Package}|${Version}", filePath)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to read %s, it is not a valid .deb file", filePath))
+		return
+	}
+
+	packageName, version, _ := strings.Cut(strings.TrimSpace(string(stdout)), "|")
+	if packageName != pkgConfiguration.Name {
+		err = errors.New(fmt.Sprintf("%s installs package %s, not %s", filePath, packageName, pkgConfiguration.Name))
+	} else if pkgConfiguration.Version != "" && pkgConfiguration.Version != "latest" && pkgConfiguration.Version != version {
+		err = errors.New(fmt.Sprintf("%s is version %s of %s, not %s", filePath, version, pkgConfiguration.Name, pkgConfiguration.Version))
+	}
+
+	return
+}
+
+// checkSHA256 compares the checksum of the file with the one of the
+// configuration, when there is one.
+func checkSHA256(pkgConfiguration *models.PackageConfiguration, filePath string) (err error) {
+	if pkgConfiguration.Deb.SHA256 == "" {
+		return
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return
+	}
+	sum := sha256.Sum256(content)
+	if checksum := hex.EncodeToString(sum[:]); !strings.EqualFold(checksum, pkgConfiguration.Deb.SHA256) {
+		err = errors.New(fmt.Sprintf("The checksum of %s is %s, expected %s", filePath, checksum, pkgConfiguration.Deb.SHA256))
+	}
+
+	return
+}
+
+func (deb *DebProvider) buildCommand(subCommand string, options ...string) (name string, args []string) {
+	name = deb.Command
+	if deb.RequiresRoot == true {
+		name = "sudo"
+		args = append(args, deb.Command)
+	}
+
+	args = append(args, subCommand, "-y")
+
+	if len(options) > 0 {
+		args = append(args, options...)
+	}
+
+	return
+}
+
+func NewDebProvider() *DebProvider {
+	return &DebProvider{
+		AbstractProvider: &AbstractProvider{
+			Command:          "apt-get",
+			InstallCommand:   "install",
+			RemoveCommand:    "remove",
+			UpdateCommand:    "",
+			UpgradeCommand:   "install",
+			CleanCommand:     "",
+			RequiresRoot:     true,
+			Lock:             "dpkg",
+			Executor:         NewExecExecutor(),
+			VersionSeparator: "",
+		},
+	}
+}
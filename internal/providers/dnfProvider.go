@@ -0,0 +1,164 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"qrobcis/pkgsmanager/internal/models"
+	"strings"
+)
+
+type DnfProvider struct {
+	*AbstractProvider
+}
+
+func (dnf *DnfProvider) InstallPackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	packageNameVersionned := pkgConfiguration.Name
+	if pkgConfiguration.Version != "" {
+		packageNameVersionned = pkgConfiguration.Name + dnf.VersionSeparator + pkgConfiguration.Version
+	}
+
+	name, args := dnf.buildCommand(dnf.InstallCommand, true, packageNameVersionned)
+	stderr, err := dnf.runCommand(PlannedStep{Description: "Install " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to install %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (dnf *DnfProvider) RemovePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	name, args := dnf.buildCommand(dnf.RemoveCommand, true, pkgConfiguration.Name)
+	stderr, err := dnf.runCommand(PlannedStep{Description: "Remove " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to remove %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (dnf *DnfProvider) UpgradePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	name, args := dnf.buildCommand(dnf.UpgradeCommand, true, pkgConfiguration.Name)
+	stderr, err := dnf.runCommand(PlannedStep{Description: "Upgrade " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to upgrade %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (dnf *DnfProvider) UpdateRegistry() (err error, cmdErr error) {
+	name, args := dnf.buildCommand(dnf.UpdateCommand, false)
+	stderr, err := dnf.runCommand(PlannedStep{Description: "Update " + dnf.Command + " registry"}, name, args...)
+	if err != nil {
+		err = errors.New("failed to update dnf metadata")
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (dnf *DnfProvider) CleanRegistry() (err error, cmdErr error) {
+	name, args := dnf.buildCommand(dnf.CleanCommand, false, "packages")
+	stderr, err := dnf.runCommand(PlannedStep{Description: "Clean " + dnf.Command + " registry"}, name, args...)
+	if err != nil {
+		err = errors.New("failed to clean dnf cache")
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (dnf *DnfProvider) IsInstalled(pkgConfiguration *models.PackageConfiguration) (installed bool, err error) {
+	version, err := dnf.InstalledVersion(pkgConfiguration)
+	installed = version != ""
+
+	return
+}
+
+func (dnf *DnfProvider) InstalledVersion(pkgConfiguration *models.PackageConfiguration) (version string, err error) {
+	return rpmInstalledVersion(dnf.AbstractProvider, pkgConfiguration)
+}
+
+// rpmInstalledVersion asks rpm for the installed version of the package. The
+// release is only part of it when the configured version has one too, so both
+// "1.2.3" and "1.2.3-1.fc40" pins can be compared.
+func rpmInstalledVersion(abstractProvider *AbstractProvider, pkgConfiguration *models.PackageConfiguration) (version string, err error) {
+	stdout, queryErr := abstractProvider.queryCommand("rpm", "-q", "--qf", "%{VERSION}|%{RELEASE}", pkgConfiguration.Name)
+	if queryErr != nil {
+		// rpm -q exits with status 1 when the package is not installed.
+		var exitErr *ExitError
+		if !errors.As(queryErr, &exitErr) {
+			err = queryErr
+		}
+		return
+	}
+
+	installedVersion, release, _ := strings.Cut(strings.TrimSpace(string(stdout)), "|")
+	version = installedVersion
+	if strings.Contains(pkgConfiguration.Version, "-") {
+		version = installedVersion + "-" + release
+	}
+
+	return
+}
+
+func (dnf *DnfProvider) buildCommand(subCommand string, autoApprove bool, options ...string) (name string, args []string) {
+	name = dnf.Command
+	if dnf.RequiresRoot == true {
+		name = "sudo"
+		args = append(args, dnf.Command)
+	}
+	args = append(args, subCommand)
+
+	if autoApprove == true {
+		args = append(args, "-y")
+	}
+
+	if len(options) > 0 {
+		args = append(args, options...)
+	}
+
+	return
+}
+
+func NewDnfProvider() *DnfProvider {
+	return &DnfProvider{
+		&AbstractProvider{
+			Command:          "dnf",
+			InstallCommand:   "install",
+			RemoveCommand:    "remove",
+			UpdateCommand:    "makecache",
+			UpgradeCommand:   "upgrade",
+			CleanCommand:     "clean",
+			RequiresRoot:     true,
+			Lock:             "rpm",
+			Executor:         NewExecExecutor(),
+			VersionSeparator: "-",
+		},
+	}
+}
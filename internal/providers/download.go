@@ -0,0 +1,66 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxDownloadSize bounds the files downloaded by the providers.
+const maxDownloadSize = 512 << 20
+
+// defaultHTTPClient is used by the providers whose HTTPClient is not set.
+var defaultHTTPClient = &http.Client{Timeout: 5 * time.Minute}
+
+// download fetches url with the provider HTTP client. Downloads only read
+// from the network, so they are done in dry-run mode too.
+func (provider *AbstractProvider) download(url string) (content []byte, err error) {
+	client := provider.HTTPClient
+	if client == nil {
+		client = defaultHTTPClient
+	}
+
+	response, err := client.Get(url)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to download %s: %s", url, err))
+		return
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		err = errors.New(fmt.Sprintf("Failed to download %s: %s", url, response.Status))
+		return
+	}
+	content, err = io.ReadAll(io.LimitReader(response.Body, maxDownloadSize+1))
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to download %s: %s", url, err))
+	} else if len(content) > maxDownloadSize {
+		content, err = nil, errors.New(fmt.Sprintf("Failed to download %s: larger than %d bytes", url, maxDownloadSize))
+	}
+
+	return
+}
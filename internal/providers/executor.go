@@ -0,0 +1,170 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package providers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Command is a command line run by a provider.
+type Command struct {
+	Name  string
+	Args  []string
+	Stdin []byte
+}
+
+func (command Command) String() string {
+	return strings.Join(append([]string{command.Name}, command.Args...), " ")
+}
+
+// ExitError is returned by an Executor when the command ran but exited with a
+// non-zero status, so providers can tell "not installed" answers from failures
+// to run the command at all.
+type ExitError struct {
+	Command  string
+	ExitCode int
+}
+
+func (exitErr *ExitError) Error() string {
+	return fmt.Sprintf("%s exited with status %d", exitErr.Command, exitErr.ExitCode)
+}
+
+// Executor runs the commands of the providers.
+type Executor interface {
+	Run(command Command) (stdout []byte, stderr string, err error)
+	// LookPath finds a command in the PATH, as exec.LookPath does.
+	LookPath(file string) (path string, err error)
+}
+
+// ExecExecutor runs commands on the system. Commands are killed after Timeout
+// when it is set.
+type ExecExecutor struct {
+	Timeout time.Duration
+}
+
+func NewExecExecutor() *ExecExecutor {
+	return &ExecExecutor{}
+}
+
+func (executor *ExecExecutor) LookPath(file string) (path string, err error) {
+	return exec.LookPath(file)
+}
+
+func (executor *ExecExecutor) Run(command Command) (stdout []byte, stderr string, err error) {
+	ctx := context.Background()
+	if executor.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, executor.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, command.Name, command.Args...)
+	if command.Stdin != nil {
+		cmd.Stdin = bytes.NewReader(command.Stdin)
+	}
+	outBuffer := new(bytes.Buffer)
+	errBuffer := new(bytes.Buffer)
+	cmd.Stdout = outBuffer
+	cmd.Stderr = errBuffer
+
+	err = cmd.Run()
+	stdout = outBuffer.Bytes()
+	stderr = errBuffer.String()
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = errors.New(fmt.Sprintf("%s timed out after %s", command.Name, executor.Timeout))
+		return
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		err = &ExitError{Command: command.Name, ExitCode: exitErr.ExitCode()}
+	}
+
+	return
+}
+
+// RecordedOutput is what a RecordingExecutor answers to a command.
+type RecordedOutput struct {
+	Stdout string
+	Stderr string
+	Err    error
+}
+
+// RecordingExecutor is a fake Executor keeping every command it is asked to
+// run. Commands listed in Outputs, keyed by their full command line, get the
+// recorded answer; any other command succeeds with no output. Every command
+// is found in the PATH but the ones listed in Missing.
+type RecordingExecutor struct {
+	Commands []Command
+	Outputs  map[string]RecordedOutput
+	Missing  map[string]bool
+	mutex    sync.Mutex
+}
+
+func NewRecordingExecutor() *RecordingExecutor {
+	return &RecordingExecutor{
+		Outputs: make(map[string]RecordedOutput),
+		Missing: make(map[string]bool),
+	}
+}
+
+func (executor *RecordingExecutor) LookPath(file string) (path string, err error) {
+	if executor.Missing[file] {
+		return "", exec.ErrNotFound
+	}
+
+	return "/usr/bin/" + file, nil
+}
+
+func (executor *RecordingExecutor) Run(command Command) (stdout []byte, stderr string, err error) {
+	executor.mutex.Lock()
+	defer executor.mutex.Unlock()
+
+	executor.Commands = append(executor.Commands, command)
+	if output, found := executor.Outputs[command.String()]; found {
+		stdout = []byte(output.Stdout)
+		stderr = output.Stderr
+		err = output.Err
+	}
+
+	return
+}
+
+// CommandLines returns the recorded commands as plain command lines.
+func (executor *RecordingExecutor) CommandLines() (commandLines []string) {
+	executor.mutex.Lock()
+	defer executor.mutex.Unlock()
+
+	for _, command := range executor.Commands {
+		commandLines = append(commandLines, command.String())
+	}
+
+	return
+}
@@ -0,0 +1,309 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"qrobcis/pkgsmanager/internal/models"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	flatpakScopeUser   = "user"
+	flatpakScopeSystem = "system"
+
+	defaultFlatpakRemote = "flathub"
+)
+
+var flatpakCommit = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+type FlatpakProvider struct {
+	*AbstractProvider
+}
+
+// InstallPackage installs the application from its remote, adding the remote
+// first when it is missing. The version is either a branch or a commit: a
+// branch is part of the installed ref, a commit is checked out afterwards.
+func (flatpak *FlatpakProvider) InstallPackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	scope, err := flatpak.scope(pkgConfiguration)
+	if err != nil {
+		return
+	}
+
+	err, cmdErr = flatpak.addRemote(pkgConfiguration, scope)
+	if err != nil {
+		return
+	}
+
+	ref := pkgConfiguration.Name
+	commit := ""
+	if flatpakCommit.MatchString(pkgConfiguration.Version) {
+		commit = pkgConfiguration.Version
+	} else if pkgConfiguration.Version != "" {
+		ref = pkgConfiguration.Name + flatpak.VersionSeparator + pkgConfiguration.Version
+	}
+
+	name, args := flatpak.buildCommand(flatpak.InstallCommand, scope, true, flatpak.remote(pkgConfiguration), ref)
+	stderr, err := flatpak.runCommand(PlannedStep{Description: "Install " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to install %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+		return
+	}
+
+	if commit != "" {
+		name, args = flatpak.buildCommand(flatpak.UpdateCommand, scope, true, "--commit="+commit, pkgConfiguration.Name)
+		stderr, err = flatpak.runCommand(PlannedStep{Description: "Check out commit " + commit + " of " + pkgConfiguration.Name}, name, args...)
+		if err != nil {
+			err = errors.New(fmt.Sprintf("Failed to check out commit %s of %s", commit, pkgConfiguration.Name))
+			cmdErr = errors.New(stderr)
+		}
+	}
+
+	return
+}
+
+func (flatpak *FlatpakProvider) RemovePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	scope, err := flatpak.scope(pkgConfiguration)
+	if err != nil {
+		return
+	}
+
+	name, args := flatpak.buildCommand(flatpak.RemoveCommand, scope, true, pkgConfiguration.Name)
+	stderr, err := flatpak.runCommand(PlannedStep{Description: "Remove " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to remove %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (flatpak *FlatpakProvider) UpgradePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	scope, err := flatpak.scope(pkgConfiguration)
+	if err != nil {
+		return
+	}
+
+	name, args := flatpak.buildCommand(flatpak.UpgradeCommand, scope, true, pkgConfiguration.Name)
+	stderr, err := flatpak.runCommand(PlannedStep{Description: "Upgrade " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to upgrade %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (flatpak *FlatpakProvider) UpdateRegistry() (err error, cmdErr error) {
+	name, args := flatpak.buildCommand(flatpak.UpdateCommand, "", false, "--appstream")
+	stderr, err := flatpak.runCommand(PlannedStep{Description: "Update " + flatpak.Command + " registry"}, name, args...)
+	if err != nil {
+		err = errors.New("failed to update flatpak appstream data")
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (flatpak *FlatpakProvider) CleanRegistry() (err error, cmdErr error) {
+	name, args := flatpak.buildCommand(flatpak.CleanCommand, "", true, "--unused")
+	stderr, err := flatpak.runCommand(PlannedStep{Description: "Clean " + flatpak.Command + " registry"}, name, args...)
+	if err != nil {
+		err = errors.New("failed to remove unused flatpak runtimes")
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (flatpak *FlatpakProvider) IsInstalled(pkgConfiguration *models.PackageConfiguration) (installed bool, err error) {
+	_, installed, err = flatpak.installedApplication(pkgConfiguration)
+
+	return
+}
+
+// InstalledVersion returns what the configured version is compared with: the
+// active commit for a commit pin, the branch for a branch pin and the
+// application version otherwise.
+func (flatpak *FlatpakProvider) InstalledVersion(pkgConfiguration *models.PackageConfiguration) (version string, err error) {
+	columns, installed, err := flatpak.installedApplication(pkgConfiguration)
+	if err != nil || !installed {
+		return
+	}
+
+	if flatpakCommit.MatchString(pkgConfiguration.Version) {
+		version = columns[3]
+	} else if pkgConfiguration.Version != "" {
+		version = columns[2]
+	} else {
+		version = columns[1]
+	}
+
+	return
+}
+
+// PinsVersion is true for a commit only, a branch being followed as it gets
+// updates.
+func (flatpak *FlatpakProvider) PinsVersion(pkgConfiguration *models.PackageConfiguration) bool {
+	return flatpakCommit.MatchString(pkgConfiguration.ConfiguredVersion)
+}
+
+// LockPackage records the active commit of the application, the version
+// column of flatpak list being informative only and not installable.
+func (flatpak *FlatpakProvider) LockPackage(pkgConfiguration *models.PackageConfiguration) (locked LockedVersion, err error) {
+	columns, installed, err := flatpak.installedApplication(pkgConfiguration)
+	if err != nil || !installed {
+		return
+	}
+	locked.Version = columns[3]
+
+	return
+}
+
+// installedApplication returns the application, version, branch and active
+// commit columns of flatpak list for the package.
+func (flatpak *FlatpakProvider) installedApplication(pkgConfiguration *models.PackageConfiguration) (columns []string, installed bool, err error) {
+	scope, err := flatpak.scope(pkgConfiguration)
+	if err != nil {
+		return
+	}
+
+	stdout, err := flatpak.queryCommand(flatpak.Command, "list", "--app", "--"+scope, "--columns=application,version,branch,active:f")
+	if err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(string(stdout), "\n") {
+		columns = strings.Split(line, "\t")
+		if len(columns) == 4 && columns[0] == pkgConfiguration.Name {
+			installed = true
+			return
+		}
+	}
+	columns = nil
+
+	return
+}
+
+// addRemote adds the remote of the package when it has a URL and is missing
+// from the scope the package is installed in.
+func (flatpak *FlatpakProvider) addRemote(pkgConfiguration *models.PackageConfiguration, scope string) (err error, cmdErr error) {
+	if pkgConfiguration.Flatpak.RemoteURL == "" {
+		return
+	}
+
+	remote := flatpak.remote(pkgConfiguration)
+	stdout, err := flatpak.queryCommand(flatpak.Command, "remotes", "--"+scope, "--columns=name")
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to list flatpak remotes for %s", pkgConfiguration.Name))
+		return
+	}
+	if slices.Contains(strings.Fields(string(stdout)), remote) {
+		return
+	}
+
+	name, args := flatpak.buildCommand("remote-add", scope, false, "--if-not-exists", remote, pkgConfiguration.Flatpak.RemoteURL)
+	stderr, err := flatpak.runCommand(PlannedStep{
+		Description: "Add flatpak remote " + remote,
+		URL:         pkgConfiguration.Flatpak.RemoteURL,
+	}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to add flatpak remote %s", remote))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+// RunsAsRoot tells whether the package is managed with sudo, as system-wide
+// installs are.
+func (flatpak *FlatpakProvider) RunsAsRoot(pkgConfiguration *models.PackageConfiguration) bool {
+	scope, _ := flatpak.scope(pkgConfiguration)
+
+	return flatpak.RequiresRoot || scope == flatpakScopeSystem
+}
+
+func (flatpak *FlatpakProvider) remote(pkgConfiguration *models.PackageConfiguration) string {
+	if pkgConfiguration.Flatpak.Remote != "" {
+		return pkgConfiguration.Flatpak.Remote
+	}
+
+	return defaultFlatpakRemote
+}
+
+func (flatpak *FlatpakProvider) scope(pkgConfiguration *models.PackageConfiguration) (scope string, err error) {
+	scope = pkgConfiguration.Flatpak.Scope
+	if scope == "" {
+		scope = flatpakScopeUser
+	}
+	if scope != flatpakScopeUser && scope != flatpakScopeSystem {
+		err = errors.New(fmt.Sprintf("Flatpak scope not supported for %s: %s", pkgConfiguration.Name, scope))
+	}
+
+	return
+}
+
+// buildCommand prefixes the command with sudo for system-wide installs. An
+// empty scope leaves the choice of installation to flatpak.
+func (flatpak *FlatpakProvider) buildCommand(subCommand string, scope string, autoApprove bool, options ...string) (name string, args []string) {
+	name = flatpak.Command
+	if flatpak.RequiresRoot == true || scope == flatpakScopeSystem {
+		name = "sudo"
+		args = append(args, flatpak.Command)
+	}
+	args = append(args, subCommand)
+
+	if scope != "" {
+		args = append(args, "--"+scope)
+	}
+
+	if autoApprove == true {
+		args = append(args, "-y", "--noninteractive")
+	}
+
+	if len(options) > 0 {
+		args = append(args, options...)
+	}
+
+	return
+}
+
+func NewFlatpakProvider() *FlatpakProvider {
+	return &FlatpakProvider{
+		&AbstractProvider{
+			Command:          "flatpak",
+			InstallCommand:   "install",
+			RemoveCommand:    "uninstall",
+			UpdateCommand:    "update",
+			UpgradeCommand:   "update",
+			CleanCommand:     "uninstall",
+			RequiresRoot:     false,
+			Executor:         NewExecExecutor(),
+			VersionSeparator: "//",
+		},
+	}
+}
@@ -23,11 +23,11 @@ THE SOFTWARE.
 package providers
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
-	"os/exec"
 	"qrobcis/pkgsmanager/internal/models"
+	"slices"
+	"strings"
 )
 
 type GemProvider struct {
@@ -46,14 +46,41 @@ func (gem *GemProvider) InstallPackage(pkgConfiguration *models.PackageConfigura
 
 	name, args := gem.buildCommand(gem.InstallCommand, packageArgs...)
 
-	cmd := exec.Command(name, args...)
-
-	errBuffer := new(bytes.Buffer)
-	cmd.Stderr = errBuffer
-	err = cmd.Run()
+	stderr, err := gem.runCommand(PlannedStep{Description: "Install " + pkgConfiguration.Name}, name, args...)
 	if err != nil {
 		err = errors.New(fmt.Sprintf("Failed to install %s", pkgConfiguration.Name))
-		cmdErr = errors.New(errBuffer.String())
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+// RemovePackage uninstalls the pinned version when one is configured, every
+// installed version otherwise, along with the gem executables.
+func (gem *GemProvider) RemovePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	packageArgs := []string{pkgConfiguration.Name, "-x"}
+	if pkgConfiguration.Version != "" {
+		packageArgs = append(packageArgs, "-v", pkgConfiguration.Version)
+	} else {
+		packageArgs = append(packageArgs, "-a")
+	}
+
+	name, args := gem.buildCommand(gem.RemoveCommand, packageArgs...)
+	stderr, err := gem.runCommand(PlannedStep{Description: "Remove " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to remove %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (gem *GemProvider) UpgradePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	name, args := gem.buildCommand(gem.UpgradeCommand, pkgConfiguration.Name)
+	stderr, err := gem.runCommand(PlannedStep{Description: "Upgrade " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to upgrade %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
 	}
 
 	return
@@ -67,6 +94,79 @@ func (gem *GemProvider) CleanRegistry() (err error, cmdErr error) {
 	return
 }
 
+func (gem *GemProvider) IsInstalled(pkgConfiguration *models.PackageConfiguration) (installed bool, err error) {
+	versions, err := gem.installedVersions(pkgConfiguration.Name)
+	installed = len(versions) > 0
+
+	return
+}
+
+// InstalledVersion returns the requested version when it is among the
+// installed ones, otherwise the most recent installed version.
+func (gem *GemProvider) InstalledVersion(pkgConfiguration *models.PackageConfiguration) (version string, err error) {
+	versions, err := gem.installedVersions(pkgConfiguration.Name)
+	if err != nil || len(versions) == 0 {
+		return
+	}
+
+	if pkgConfiguration.Version != "" && slices.Contains(versions, pkgConfiguration.Version) {
+		version = pkgConfiguration.Version
+	} else {
+		version = versions[0]
+	}
+
+	return
+}
+
+// installedVersions parses the "name (1.2.0, default: 1.1.0)" lines printed by
+// gem list, most recent version first.
+func (gem *GemProvider) installedVersions(packageName string) (versions []string, err error) {
+	stdout, err := gem.queryCommand(gem.Command, "list", "--local", "--exact", packageName)
+	if err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(string(stdout), "\n") {
+		listedName, versionList, found := strings.Cut(strings.TrimSpace(line), " (")
+		if !found || listedName != packageName {
+			continue
+		}
+		for _, listedVersion := range strings.Split(strings.TrimSuffix(versionList, ")"), ",") {
+			listedVersion = strings.TrimPrefix(strings.TrimSpace(listedVersion), "default: ")
+			if listedVersion != "" {
+				versions = append(versions, listedVersion)
+			}
+		}
+	}
+
+	return
+}
+
+// ListInstalled returns the installed gems with their most recent version.
+// Gems only installed as default gems ship with Ruby and are left out.
+func (gem *GemProvider) ListInstalled() (packages []InstalledPackage, err error) {
+	stdout, err := gem.queryCommand(gem.Command, "list", "--local")
+	if err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(string(stdout), "\n") {
+		name, versionList, found := strings.Cut(strings.TrimSpace(line), " (")
+		if !found {
+			continue
+		}
+		for _, listedVersion := range strings.Split(strings.TrimSuffix(versionList, ")"), ",") {
+			listedVersion = strings.TrimSpace(listedVersion)
+			if listedVersion != "" && !strings.HasPrefix(listedVersion, "default: ") {
+				packages = append(packages, InstalledPackage{Name: name, Version: listedVersion})
+				break
+			}
+		}
+	}
+
+	return
+}
+
 func (gem *GemProvider) buildCommand(subCommand string, options ...string) (name string, args []string) {
 	name = gem.Command
 	if gem.RequiresRoot == true {
@@ -88,9 +188,12 @@ func NewGemProvider() *GemProvider {
 		&AbstractProvider{
 			Command:          "gem",
 			InstallCommand:   "install",
+			RemoveCommand:    "uninstall",
 			UpdateCommand:    "",
+			UpgradeCommand:   "update",
 			CleanCommand:     "",
 			RequiresRoot:     true,
+			Executor:         NewExecExecutor(),
 			VersionSeparator: "",
 		},
 	}
@@ -23,13 +23,18 @@ THE SOFTWARE.
 package providers
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
-	"os/exec"
+	"os"
+	"path"
+	"path/filepath"
 	"qrobcis/pkgsmanager/internal/models"
+	"regexp"
+	"strings"
 )
 
+var majorVersionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
 type GoProvider struct {
 	*AbstractProvider
 }
@@ -37,57 +42,177 @@ type GoProvider struct {
 func (golang *GoProvider) InstallPackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
 	packageNameVersionned := ""
 	if pkgConfiguration.Version != "" {
-		packageNameVersionned = pkgConfiguration.Name + "@" + pkgConfiguration.Version
+		packageNameVersionned = pkgConfiguration.Name + golang.VersionSeparator + pkgConfiguration.Version
 	} else {
 		packageNameVersionned = pkgConfiguration.Name
 	}
 
 	name, args := golang.buildCommand(golang.InstallCommand, packageNameVersionned)
-	cmd := exec.Command(name, args...)
-
-	errBuffer := new(bytes.Buffer)
-	cmd.Stderr = errBuffer
-	err = cmd.Run()
+	stderr, err := golang.runCommand(PlannedStep{Description: "Install " + pkgConfiguration.Name}, name, args...)
 	if err != nil {
 		err = errors.New(fmt.Sprintf("Failed to install %s", pkgConfiguration.Name))
-		cmdErr = errors.New(errBuffer.String())
+		cmdErr = errors.New(stderr)
 	}
 
 	return
 }
 
-func (golang *GoProvider) UpdateRegistry() (err error, cmdErr error) {
+// RemovePackage deletes the binary installed by go install, go having no
+// uninstall command of its own.
+func (golang *GoProvider) RemovePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	binaryPath, err := golang.binaryPath(pkgConfiguration.Name)
+	if err != nil {
+		return
+	}
+
+	if golang.DryRun {
+		golang.recordStep(PlannedStep{Description: "Remove " + pkgConfiguration.Name, File: binaryPath})
+		return
+	}
+
+	removeErr := os.Remove(binaryPath)
+	if removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) {
+		err = errors.New(fmt.Sprintf("Failed to remove %s", pkgConfiguration.Name))
+		cmdErr = removeErr
+	}
+
 	return
 }
 
-func (golang *GoProvider) UpgradePackages() (err error, cmdErr error) {
-	name, args := golang.buildCommand(golang.UpdateCommand)
-	cmd := exec.Command(name, args...)
-	errBuffer := new(bytes.Buffer)
-	cmd.Stderr = errBuffer
-	err = cmd.Run()
+func (golang *GoProvider) UpgradePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	name, args := golang.buildCommand(golang.UpgradeCommand, pkgConfiguration.Name+golang.VersionSeparator+"latest")
+	stderr, err := golang.runCommand(PlannedStep{Description: "Upgrade " + pkgConfiguration.Name}, name, args...)
 	if err != nil {
-		err = errors.New("failed to update go sources")
-		cmdErr = errors.New(errBuffer.String())
+		err = errors.New(fmt.Sprintf("Failed to upgrade %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
 	}
 
 	return
 }
 
+func (golang *GoProvider) UpdateRegistry() (err error, cmdErr error) {
+	return
+}
+
 func (golang *GoProvider) CleanRegistry() (err error, cmdErr error) {
 	name, args := golang.buildCommand(golang.CleanCommand)
-	cmd := exec.Command(name, args...)
-	errBuffer := new(bytes.Buffer)
-	cmd.Stderr = errBuffer
-	err = cmd.Run()
+	stderr, err := golang.runCommand(PlannedStep{Description: "Clean " + golang.Command + " registry"}, name, args...)
 	if err != nil {
 		err = errors.New("failed to update go sources")
-		cmdErr = errors.New(errBuffer.String())
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (golang *GoProvider) IsInstalled(pkgConfiguration *models.PackageConfiguration) (installed bool, err error) {
+	binaryPath, err := golang.binaryPath(pkgConfiguration.Name)
+	if err != nil {
+		return
+	}
+
+	_, statErr := os.Stat(binaryPath)
+	installed = statErr == nil
+
+	return
+}
+
+func (golang *GoProvider) InstalledVersion(pkgConfiguration *models.PackageConfiguration) (version string, err error) {
+	installed, err := golang.IsInstalled(pkgConfiguration)
+	if err != nil || !installed {
+		return
+	}
+
+	binaryPath, _ := golang.binaryPath(pkgConfiguration.Name)
+	stdout, err := golang.queryCommand(golang.Command, "version", "-m", binaryPath)
+	if err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(string(stdout), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && fields[0] == "mod" {
+			version = fields[2]
+			break
+		}
+	}
+
+	return
+}
+
+// binaryPath returns where go install puts the binary built from packageName.
+func (golang *GoProvider) binaryPath(packageName string) (binaryPath string, err error) {
+	binDirectory, err := golang.binDirectory()
+	binaryPath = filepath.Join(binDirectory, goBinaryName(packageName))
+
+	return
+}
+
+// binDirectory returns where go install puts binaries: $GOBIN, or
+// $GOPATH/bin when GOBIN is unset.
+func (golang *GoProvider) binDirectory() (binDirectory string, err error) {
+	stdout, err := golang.queryCommand(golang.Command, "env", "GOBIN", "GOPATH")
+	if err != nil {
+		err = errors.New("failed to read go environment")
+		return
+	}
+
+	env := strings.Split(string(stdout), "\n")
+	binDirectory = strings.TrimSpace(env[0])
+	if binDirectory == "" && len(env) > 1 {
+		gopath := filepath.SplitList(strings.TrimSpace(env[1]))
+		if len(gopath) > 0 {
+			binDirectory = filepath.Join(gopath[0], "bin")
+		}
+	}
+
+	return
+}
+
+// ListInstalled returns the packages the binaries of the bin directory were
+// built from, read from the build information go version -m prints:
+//
+//	/home/user/go/bin/gopls: go1.22.1
+//		path	golang.org/x/tools/gopls
+//		mod	golang.org/x/tools/gopls	v0.15.0	h1:...
+func (golang *GoProvider) ListInstalled() (packages []InstalledPackage, err error) {
+	binDirectory, err := golang.binDirectory()
+	if err != nil {
+		return
+	}
+	if _, statErr := os.Stat(binDirectory); errors.Is(statErr, os.ErrNotExist) {
+		return
+	}
+
+	stdout, err := golang.queryCommand(golang.Command, "version", "-m", binDirectory)
+	if err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(string(stdout), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "path" {
+			packages = append(packages, InstalledPackage{Name: fields[1]})
+		} else if len(fields) >= 3 && fields[0] == "mod" && len(packages) > 0 && fields[2] != "(devel)" {
+			packages[len(packages)-1].Version = fields[2]
+		}
 	}
 
 	return
 }
 
+// goBinaryName mirrors go install naming: the last path element, skipping a
+// trailing major version suffix such as /v2.
+func goBinaryName(packageName string) string {
+	packagePath := strings.TrimSuffix(packageName, "/...")
+	binaryName := path.Base(packagePath)
+	if majorVersionSuffix.MatchString(binaryName) && path.Dir(packagePath) != "." {
+		binaryName = path.Base(path.Dir(packagePath))
+	}
+
+	return binaryName
+}
+
 func (golang *GoProvider) buildCommand(subCommand string, options ...string) (name string, args []string) {
 	name = golang.Command
 	if golang.RequiresRoot == true {
@@ -113,9 +238,12 @@ func NewGoProvider() *GoProvider {
 		&AbstractProvider{
 			Command:          "go",
 			InstallCommand:   "install",
+			RemoveCommand:    "",
 			UpdateCommand:    "",
+			UpgradeCommand:   "install",
 			CleanCommand:     "clean",
 			RequiresRoot:     false,
+			Executor:         NewExecExecutor(),
 			VersionSeparator: "@",
 		},
 	}
@@ -0,0 +1,271 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package providers
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"slices"
+	"strings"
+)
+
+const (
+	// publicKeyTag is the OpenPGP packet tag of a primary public key.
+	publicKeyTag = 6
+	armorBegin   = "-----BEGIN PGP PUBLIC KEY BLOCK-----"
+	armorEnd     = "-----END PGP PUBLIC KEY BLOCK-----"
+)
+
+// dearmor returns the binary form of an OpenPGP key, decoding it when it is
+// ASCII armored. Binary keys are returned as they are.
+func dearmor(key []byte) (keyring []byte, err error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(key), []byte("-----BEGIN ")) {
+		return key, nil
+	}
+
+	// A file may hold several armored blocks, their keys are concatenated.
+	var body strings.Builder
+	var checksum string
+	inBlock, inHeaders := false, false
+	scanner := bufio.NewScanner(bytes.NewReader(key))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == armorBegin:
+			inBlock, inHeaders = true, true
+			body.Reset()
+			checksum = ""
+		case !inBlock:
+			continue
+		case line == armorEnd:
+			var block []byte
+			if block, err = base64.StdEncoding.DecodeString(body.String()); err != nil {
+				err = errors.New(fmt.Sprintf("invalid armored key: %s", err))
+				return
+			}
+			if checksum != "" && checksum != armorChecksum(block) {
+				err = errors.New("invalid armored key: checksum mismatch")
+				return
+			}
+			keyring = append(keyring, block...)
+			inBlock = false
+		case inHeaders && strings.Contains(line, ": "):
+			continue
+		case line == "":
+			inHeaders = false
+		case strings.HasPrefix(line, "="):
+			// The checksum closes the block, base64 never starts with =.
+			checksum = line[1:]
+		default:
+			inHeaders = false
+			body.WriteString(line)
+		}
+	}
+	if len(keyring) == 0 {
+		err = errors.New("no public key block found in the armored key")
+	}
+
+	return
+}
+
+// armorChecksum computes the CRC-24 of a decoded armored block, encoded as
+// the checksum line that ends the block, without its = prefix.
+func armorChecksum(block []byte) string {
+	crc := uint32(0xb704ce)
+	for _, b := range block {
+		crc ^= uint32(b) << 16
+		for i := 0; i < 8; i++ {
+			crc <<= 1
+			if crc&0x1000000 != 0 {
+				crc ^= 0x1864cfb
+			}
+		}
+	}
+
+	return base64.StdEncoding.EncodeToString([]byte{byte(crc >> 16), byte(crc >> 8), byte(crc)})
+}
+
+// keyFingerprints returns the fingerprints of the primary keys of a binary
+// OpenPGP keyring, in uppercase hexadecimal.
+func keyFingerprints(keyring []byte) (fingerprints []string, err error) {
+	for len(keyring) > 0 {
+		var tag byte
+		var body []byte
+		tag, body, keyring, err = readPacket(keyring)
+		if err != nil {
+			return
+		}
+		if tag != publicKeyTag {
+			continue
+		}
+
+		var fingerprint string
+		if fingerprint, err = keyFingerprint(body); err != nil {
+			return
+		}
+		fingerprints = append(fingerprints, fingerprint)
+	}
+	if len(fingerprints) == 0 {
+		err = errors.New("no public key found")
+	}
+
+	return
+}
+
+// readPacket splits the first OpenPGP packet off data, reading its header in
+// the new or the legacy format.
+func readPacket(data []byte) (tag byte, body []byte, rest []byte, err error) {
+	truncated := errors.New("truncated OpenPGP packet")
+	if len(data) == 0 {
+		err = truncated
+		return
+	}
+	if data[0]&0x80 == 0 {
+		err = errors.New("not an OpenPGP key")
+		return
+	}
+
+	var length, offset int
+	if data[0]&0x40 != 0 {
+		tag = data[0] & 0x3f
+		if len(data) < 2 {
+			err = truncated
+			return
+		}
+		switch first := int(data[1]); {
+		case first < 192:
+			length, offset = first, 2
+		case first < 224:
+			if len(data) < 3 {
+				err = truncated
+				return
+			}
+			length, offset = (first-192)<<8+int(data[2])+192, 3
+		case first == 255:
+			if len(data) < 6 {
+				err = truncated
+				return
+			}
+			length, offset = int(binary.BigEndian.Uint32(data[2:6])), 6
+		default:
+			err = errors.New("partial OpenPGP packets are not supported in keys")
+			return
+		}
+	} else {
+		tag = (data[0] >> 2) & 0x0f
+		switch data[0] & 0x03 {
+		case 0:
+			if len(data) < 2 {
+				err = truncated
+				return
+			}
+			length, offset = int(data[1]), 2
+		case 1:
+			if len(data) < 3 {
+				err = truncated
+				return
+			}
+			length, offset = int(binary.BigEndian.Uint16(data[1:3])), 3
+		case 2:
+			if len(data) < 5 {
+				err = truncated
+				return
+			}
+			length, offset = int(binary.BigEndian.Uint32(data[1:5])), 5
+		default:
+			length, offset = len(data)-1, 1
+		}
+	}
+	if length < 0 || offset+length > len(data) {
+		err = truncated
+		return
+	}
+
+	return tag, data[offset : offset+length], data[offset+length:], nil
+}
+
+// keyFingerprint computes the fingerprint of a public key packet: the SHA-1
+// of the packet for version 4 keys, its SHA-256 for version 5 and 6 keys.
+func keyFingerprint(body []byte) (fingerprint string, err error) {
+	if len(body) == 0 {
+		err = errors.New("empty public key packet")
+		return
+	}
+
+	var digest hash.Hash
+	switch version := body[0]; version {
+	case 4:
+		digest = sha1.New()
+		digest.Write([]byte{0x99, byte(len(body) >> 8), byte(len(body))})
+	case 5, 6:
+		digest = sha256.New()
+		prefix := byte(0x9a)
+		if version == 6 {
+			prefix = 0x9b
+		}
+		digest.Write([]byte{prefix})
+		digest.Write(binary.BigEndian.AppendUint32(nil, uint32(len(body))))
+	default:
+		err = errors.New(fmt.Sprintf("version %d keys are not supported", version))
+		return
+	}
+	digest.Write(body)
+	fingerprint = strings.ToUpper(hex.EncodeToString(digest.Sum(nil)))
+
+	return
+}
+
+// normalizeFingerprint writes a fingerprint as keyFingerprints does, without
+// the spaces and the 0x prefix it is often written with.
+func normalizeFingerprint(fingerprint string) string {
+	fingerprint = strings.TrimPrefix(strings.TrimPrefix(fingerprint, "0x"), "0X")
+
+	return strings.ToUpper(strings.ReplaceAll(fingerprint, " ", ""))
+}
+
+// hasOnlyFingerprint tells whether every primary key the key holds has the
+// fingerprint: other keys served along with the expected one would be trusted
+// for the repository too. The key may be armored.
+func hasOnlyFingerprint(key []byte, fingerprint string) (found bool, fingerprints []string, err error) {
+	keyring, err := dearmor(key)
+	if err != nil {
+		return
+	}
+	if fingerprints, err = keyFingerprints(keyring); err != nil {
+		return
+	}
+	expected := normalizeFingerprint(fingerprint)
+	found = !slices.ContainsFunc(fingerprints, func(candidate string) bool {
+		return candidate != expected
+	})
+
+	return
+}
@@ -0,0 +1,184 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package providers
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+const (
+	testKeyFingerprint  = "7EC27675CB3EBCD2C95185591AB1D870CC84C56C"
+	otherKeyFingerprint = "20D60F0827436FF50B3A5E1E6A56568D603B4390"
+)
+
+func readTestData(t *testing.T, name string) []byte {
+	t.Helper()
+	content, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return content
+}
+
+func TestDearmor(t *testing.T) {
+	armored := readTestData(t, "test-key.asc")
+	binary := readTestData(t, "test-key.gpg")
+	other := readTestData(t, "other-key.asc")
+	otherBinary, err := dearmor(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Changing the last checksum character keeps it valid base64.
+	badChecksum := bytes.Replace(armored, []byte("\n=q1TC\n"), []byte("\n=q1TD\n"), 1)
+	if bytes.Equal(badChecksum, armored) {
+		t.Fatal("test-key.asc doesn't end with the expected checksum")
+	}
+
+	tests := []struct {
+		name    string
+		key     []byte
+		want    []byte
+		wantErr string
+	}{
+		{name: "armored", key: armored, want: binary},
+		{name: "binary", key: binary, want: binary},
+		{name: "multiple blocks", key: append(append([]byte{}, armored...), other...), want: append(append([]byte{}, binary...), otherBinary...)},
+		{name: "bad checksum", key: badChecksum, wantErr: "checksum mismatch"},
+		{name: "no block", key: []byte("-----BEGIN PGP MESSAGE-----\n-----END PGP MESSAGE-----\n"), wantErr: "no public key block"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			keyring, err := dearmor(test.key)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("dearmor() error = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("dearmor() error = %v", err)
+			}
+			if !bytes.Equal(keyring, test.want) {
+				t.Fatalf("dearmor() = %x, want %x", keyring, test.want)
+			}
+		})
+	}
+}
+
+func TestReadPacket(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		wantTag  byte
+		wantBody []byte
+		wantRest []byte
+		wantErr  bool
+	}{
+		{name: "new one-octet length", data: []byte{0xc6, 0x02, 0x01, 0x02, 0xff}, wantTag: 6, wantBody: []byte{0x01, 0x02}, wantRest: []byte{0xff}},
+		{name: "new two-octet length", data: append([]byte{0xcd, 0xc0, 0x00}, bytes.Repeat([]byte{0x01}, 192)...), wantTag: 13, wantBody: bytes.Repeat([]byte{0x01}, 192), wantRest: []byte{}},
+		{name: "new five-octet length", data: []byte{0xc6, 0xff, 0x00, 0x00, 0x00, 0x01, 0x2a}, wantTag: 6, wantBody: []byte{0x2a}, wantRest: []byte{}},
+		{name: "old one-octet length", data: []byte{0x98, 0x01, 0x04}, wantTag: 6, wantBody: []byte{0x04}, wantRest: []byte{}},
+		{name: "old two-octet length", data: []byte{0x99, 0x00, 0x01, 0x04, 0xb4}, wantTag: 6, wantBody: []byte{0x04}, wantRest: []byte{0xb4}},
+		{name: "old four-octet length", data: []byte{0x9a, 0x00, 0x00, 0x00, 0x01, 0x04}, wantTag: 6, wantBody: []byte{0x04}, wantRest: []byte{}},
+		{name: "old indeterminate length", data: []byte{0xb7, 0x01, 0x02}, wantTag: 13, wantBody: []byte{0x01, 0x02}, wantRest: []byte{}},
+		{name: "empty", data: []byte{}, wantErr: true},
+		{name: "not a packet", data: []byte{0x01, 0x02}, wantErr: true},
+		{name: "truncated header", data: []byte{0xc6}, wantErr: true},
+		{name: "truncated two-octet header", data: []byte{0xc6, 0xc0}, wantErr: true},
+		{name: "truncated five-octet header", data: []byte{0xc6, 0xff, 0x00}, wantErr: true},
+		{name: "truncated old header", data: []byte{0x99, 0x00}, wantErr: true},
+		{name: "truncated body", data: []byte{0xc6, 0x05, 0x01}, wantErr: true},
+		{name: "truncated old body", data: []byte{0x98, 0x05, 0x01}, wantErr: true},
+		{name: "partial length", data: []byte{0xc6, 0xe1, 0x01}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tag, body, rest, err := readPacket(test.data)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("readPacket() = %d, %x, %x, want an error", tag, body, rest)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readPacket() error = %v", err)
+			}
+			if tag != test.wantTag || !bytes.Equal(body, test.wantBody) || !bytes.Equal(rest, test.wantRest) {
+				t.Fatalf("readPacket() = %d, %x, %x, want %d, %x, %x", tag, body, rest, test.wantTag, test.wantBody, test.wantRest)
+			}
+		})
+	}
+}
+
+func TestKeyFingerprint(t *testing.T) {
+	keyring := readTestData(t, "test-key.gpg")
+	tag, body, _, err := readPacket(keyring)
+	if err != nil || tag != publicKeyTag {
+		t.Fatalf("readPacket() = %d, %v, want a public key", tag, err)
+	}
+
+	fingerprint, err := keyFingerprint(body)
+	if err != nil {
+		t.Fatalf("keyFingerprint() error = %v", err)
+	}
+	if fingerprint != testKeyFingerprint {
+		t.Fatalf("keyFingerprint() = %s, want %s", fingerprint, testKeyFingerprint)
+	}
+
+	if _, err = keyFingerprint([]byte{3}); err == nil {
+		t.Fatal("keyFingerprint() of a version 3 key succeeded")
+	}
+}
+
+func TestHasOnlyFingerprint(t *testing.T) {
+	armored := readTestData(t, "test-key.asc")
+	both := append(append([]byte{}, armored...), readTestData(t, "other-key.asc")...)
+
+	tests := []struct {
+		name        string
+		key         []byte
+		fingerprint string
+		want        bool
+	}{
+		{name: "expected key", key: armored, fingerprint: testKeyFingerprint, want: true},
+		{name: "spaced and prefixed", key: armored, fingerprint: "0x7ec2 7675 cb3e bcd2 c951 8559 1ab1 d870 cc84 c56c", want: true},
+		{name: "other key", key: armored, fingerprint: otherKeyFingerprint, want: false},
+		{name: "extra key", key: both, fingerprint: testKeyFingerprint, want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			found, _, err := hasOnlyFingerprint(test.key, test.fingerprint)
+			if err != nil {
+				t.Fatalf("hasOnlyFingerprint() error = %v", err)
+			}
+			if found != test.want {
+				t.Fatalf("hasOnlyFingerprint() = %t, want %t", found, test.want)
+			}
+		})
+	}
+}
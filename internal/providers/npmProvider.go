@@ -23,66 +23,125 @@ THE SOFTWARE.
 package providers
 
 import (
-	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"os/exec"
 	"qrobcis/pkgsmanager/internal/models"
+	"slices"
+	"strings"
 )
 
 type NpmProvider struct {
 	*AbstractProvider
 }
 
+type npmListOutput struct {
+	Dependencies map[string]struct {
+		Version string `json:"version"`
+	} `json:"dependencies"`
+}
+
 func (npm *NpmProvider) InstallPackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
 	packageNameVersionned := ""
 	if pkgConfiguration.Version != "" {
-		packageNameVersionned = pkgConfiguration.Name + "@" + pkgConfiguration.Version
+		packageNameVersionned = pkgConfiguration.Name + npm.VersionSeparator + pkgConfiguration.Version
 	} else {
 		packageNameVersionned = pkgConfiguration.Name
 	}
 	name, args := npm.buildCommand(npm.InstallCommand, packageNameVersionned)
-	cmd := exec.Command(name, args...)
-
-	errBuffer := new(bytes.Buffer)
-	cmd.Stderr = errBuffer
-	err = cmd.Run()
+	stderr, err := npm.runCommand(PlannedStep{Description: "Install " + pkgConfiguration.Name}, name, args...)
 	if err != nil {
 		err = errors.New(fmt.Sprintf("Failed to install %s", pkgConfiguration.Name))
-		cmdErr = errors.New(errBuffer.String())
+		cmdErr = errors.New(stderr)
 	}
 
 	return
 }
 
-func (npm *NpmProvider) UpdateRegistry() (err error, cmdErr error) {
+func (npm *NpmProvider) RemovePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	name, args := npm.buildCommand(npm.RemoveCommand, pkgConfiguration.Name)
+	stderr, err := npm.runCommand(PlannedStep{Description: "Remove " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to remove %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
 	return
 }
 
-func (npm *NpmProvider) UpgradePackages() (err error, cmdErr error) {
-	name, args := npm.buildCommand(npm.UpdateCommand)
-	cmd := exec.Command(name, args...)
-	errBuffer := new(bytes.Buffer)
-	cmd.Stderr = errBuffer
-	err = cmd.Run()
+func (npm *NpmProvider) UpgradePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	name, args := npm.buildCommand(npm.UpgradeCommand, pkgConfiguration.Name+npm.VersionSeparator+"latest")
+	stderr, err := npm.runCommand(PlannedStep{Description: "Upgrade " + pkgConfiguration.Name}, name, args...)
 	if err != nil {
-		err = errors.New("failed to update npm sources")
-		cmdErr = errors.New(errBuffer.String())
+		err = errors.New(fmt.Sprintf("Failed to upgrade %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
 	}
 
 	return
 }
 
+func (npm *NpmProvider) UpdateRegistry() (err error, cmdErr error) {
+	return
+}
+
 func (npm *NpmProvider) CleanRegistry() (err error, cmdErr error) {
 	name, args := npm.buildCommand(npm.CleanCommand)
-	cmd := exec.Command(name, args...)
-	errBuffer := new(bytes.Buffer)
-	cmd.Stderr = errBuffer
-	err = cmd.Run()
+	stderr, err := npm.runCommand(PlannedStep{Description: "Clean " + npm.Command + " registry"}, name, args...)
 	if err != nil {
 		err = errors.New("failed to update apt sources")
-		cmdErr = errors.New(errBuffer.String())
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (npm *NpmProvider) IsInstalled(pkgConfiguration *models.PackageConfiguration) (installed bool, err error) {
+	version, err := npm.InstalledVersion(pkgConfiguration)
+	installed = version != ""
+
+	return
+}
+
+func (npm *NpmProvider) InstalledVersion(pkgConfiguration *models.PackageConfiguration) (version string, err error) {
+	// npm ls exits with status 1 when the package is missing but still prints
+	// a valid JSON document, so the output is parsed before looking at the error.
+	stdout, queryErr := npm.queryCommand(npm.Command, "ls", "-g", "--json", "--depth=0", pkgConfiguration.Name)
+
+	var listOutput npmListOutput
+	if jsonErr := json.Unmarshal(stdout, &listOutput); jsonErr != nil {
+		if queryErr != nil {
+			err = queryErr
+		} else {
+			err = jsonErr
+		}
+		return
+	}
+
+	if dependency, found := listOutput.Dependencies[pkgConfiguration.Name]; found {
+		version = dependency.Version
+	}
+
+	return
+}
+
+func (npm *NpmProvider) ListInstalled() (packages []InstalledPackage, err error) {
+	stdout, queryErr := npm.queryCommand(npm.Command, "ls", "-g", "--json", "--depth=0")
+
+	var listOutput npmListOutput
+	if jsonErr := json.Unmarshal(stdout, &listOutput); jsonErr != nil {
+		if queryErr != nil {
+			err = queryErr
+		} else {
+			err = jsonErr
+		}
+		return
+	}
+	for name, dependency := range listOutput.Dependencies {
+		packages = append(packages, InstalledPackage{Name: name, Version: dependency.Version})
 	}
+	slices.SortFunc(packages, func(a InstalledPackage, b InstalledPackage) int {
+		return strings.Compare(a.Name, b.Name)
+	})
 
 	return
 }
@@ -94,7 +153,7 @@ func (npm *NpmProvider) buildCommand(subCommand string, options ...string) (name
 		args = append(args, npm.Command)
 	}
 
-	if subCommand == npm.InstallCommand || subCommand == npm.UpdateCommand {
+	if subCommand == npm.InstallCommand || subCommand == npm.UpdateCommand || subCommand == npm.RemoveCommand {
 		args = append(args, subCommand, "-g")
 	} else if subCommand == npm.CleanCommand {
 		args = append(args, "cache", npm.CleanCommand)
@@ -112,9 +171,12 @@ func NewNpmProvider() *NpmProvider {
 		&AbstractProvider{
 			Command:          "npm",
 			InstallCommand:   "install",
+			RemoveCommand:    "uninstall",
 			UpdateCommand:    "update",
+			UpgradeCommand:   "install",
 			CleanCommand:     "clean",
 			RequiresRoot:     false,
+			Executor:         NewExecExecutor(),
 			VersionSeparator: "@",
 		},
 	}
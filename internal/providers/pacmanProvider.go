@@ -0,0 +1,181 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"qrobcis/pkgsmanager/internal/models"
+	"strings"
+)
+
+type PacmanProvider struct {
+	*AbstractProvider
+}
+
+// InstallPackage installs the package from the sync repositories. Those only
+// carry the latest version, so a pinned version can't be installed.
+func (pacman *PacmanProvider) InstallPackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	if pkgConfiguration.Version != "" && pkgConfiguration.Version != "latest" {
+		err = errors.New(fmt.Sprintf("Failed to install %s: pacman can't install a specific version", pkgConfiguration.Name))
+		return
+	}
+
+	name, args := pacman.buildCommand(pacman.InstallCommand, true, "--needed", pkgConfiguration.Name)
+	stderr, err := pacman.runCommand(PlannedStep{Description: "Install " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to install %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (pacman *PacmanProvider) RemovePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	name, args := pacman.buildCommand(pacman.RemoveCommand, true, pkgConfiguration.Name)
+	stderr, err := pacman.runCommand(PlannedStep{Description: "Remove " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to remove %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+// UpgradePackage reinstalls the package from the sync repositories, which
+// only carry the latest version. --needed skips it when it is up to date.
+func (pacman *PacmanProvider) UpgradePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	name, args := pacman.buildCommand(pacman.UpgradeCommand, true, "--needed", pkgConfiguration.Name)
+	stderr, err := pacman.runCommand(PlannedStep{Description: "Upgrade " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to upgrade %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (pacman *PacmanProvider) UpdateRegistry() (err error, cmdErr error) {
+	name, args := pacman.buildCommand(pacman.UpdateCommand, false)
+	stderr, err := pacman.runCommand(PlannedStep{Description: "Update " + pacman.Command + " registry"}, name, args...)
+	if err != nil {
+		err = errors.New("failed to synchronize pacman databases")
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+// UpgradeSystem refreshes the databases and upgrades every package of the
+// system, which Arch expects before packages are upgraded one by one.
+func (pacman *PacmanProvider) UpgradeSystem() (err error, cmdErr error) {
+	name, args := pacman.buildCommand("-Syu", true)
+	stderr, err := pacman.runCommand(PlannedStep{Description: "Upgrade the system with " + pacman.Command}, name, args...)
+	if err != nil {
+		err = errors.New("failed to upgrade the system with pacman")
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (pacman *PacmanProvider) CleanRegistry() (err error, cmdErr error) {
+	name, args := pacman.buildCommand(pacman.CleanCommand, true)
+	stderr, err := pacman.runCommand(PlannedStep{Description: "Clean " + pacman.Command + " registry"}, name, args...)
+	if err != nil {
+		err = errors.New("failed to clean pacman cache")
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (pacman *PacmanProvider) IsInstalled(pkgConfiguration *models.PackageConfiguration) (installed bool, err error) {
+	version, err := pacman.InstalledVersion(pkgConfiguration)
+	installed = version != ""
+
+	return
+}
+
+func (pacman *PacmanProvider) InstalledVersion(pkgConfiguration *models.PackageConfiguration) (version string, err error) {
+	stdout, queryErr := pacman.queryCommand(pacman.Command, "-Q", pkgConfiguration.Name)
+	if queryErr != nil {
+		// pacman -Q exits with status 1 when the package is not installed.
+		var exitErr *ExitError
+		if !errors.As(queryErr, &exitErr) {
+			err = queryErr
+		}
+		return
+	}
+
+	fields := strings.Fields(string(stdout))
+	if len(fields) >= 2 && fields[0] == pkgConfiguration.Name {
+		version = fields[1]
+	}
+
+	return
+}
+
+// CanPinVersion is false as the sync repositories only carry the latest
+// version of a package.
+func (pacman *PacmanProvider) CanPinVersion(pkgConfiguration *models.PackageConfiguration) bool {
+	return false
+}
+
+// buildCommand takes the pacman operation flags, such as -S or -Rns, as the
+// sub-command.
+func (pacman *PacmanProvider) buildCommand(subCommand string, autoApprove bool, options ...string) (name string, args []string) {
+	name = pacman.Command
+	if pacman.RequiresRoot == true {
+		name = "sudo"
+		args = append(args, pacman.Command)
+	}
+	args = append(args, subCommand)
+
+	if autoApprove == true {
+		args = append(args, "--noconfirm")
+	}
+
+	if len(options) > 0 {
+		args = append(args, options...)
+	}
+
+	return
+}
+
+func NewPacmanProvider() *PacmanProvider {
+	return &PacmanProvider{
+		&AbstractProvider{
+			Command:          "pacman",
+			InstallCommand:   "-S",
+			RemoveCommand:    "-Rns",
+			UpdateCommand:    "-Sy",
+			UpgradeCommand:   "-S",
+			CleanCommand:     "-Sc",
+			RequiresRoot:     true,
+			Lock:             "pacman",
+			Executor:         NewExecExecutor(),
+			VersionSeparator: "",
+		},
+	}
+}
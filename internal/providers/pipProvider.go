@@ -0,0 +1,357 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package providers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"qrobcis/pkgsmanager/internal/models"
+	"slices"
+	"strings"
+)
+
+const (
+	pipModeUser = "user"
+	pipModeVenv = "venv"
+	pipModePipx = "pipx"
+
+	defaultPythonInterpreter = "python3"
+)
+
+type PipProvider struct {
+	*AbstractProvider
+}
+
+type pipxListOutput struct {
+	Venvs map[string]struct {
+		Metadata struct {
+			MainPackage struct {
+				PackageVersion string `json:"package_version"`
+			} `json:"main_package"`
+		} `json:"metadata"`
+	} `json:"venvs"`
+}
+
+func (pip *PipProvider) InstallPackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	mode, err := pip.mode(pkgConfiguration)
+	if err != nil {
+		return
+	}
+
+	if mode == pipModeVenv {
+		err, cmdErr = pip.createVenv(pkgConfiguration)
+		if err != nil {
+			return
+		}
+	}
+
+	packageNameVersionned := pkgConfiguration.Name
+	if pkgConfiguration.Version != "" && pkgConfiguration.Version != "latest" {
+		packageNameVersionned = pkgConfiguration.Name + pip.VersionSeparator + pkgConfiguration.Version
+	}
+
+	var installArgs []string
+	if mode == pipModeUser {
+		installArgs = append(installArgs, "--user")
+	} else if mode == pipModePipx {
+		// sync only reaches this point when the installed version doesn't match,
+		// which pipx refuses to replace without --force.
+		installArgs = append(installArgs, "--force")
+		if pkgConfiguration.Pip.Interpreter != "" {
+			installArgs = append(installArgs, "--python", pkgConfiguration.Pip.Interpreter)
+		}
+	}
+	installArgs = append(installArgs, packageNameVersionned)
+
+	name, args := pip.buildCommand(pkgConfiguration, mode, pip.InstallCommand, installArgs...)
+	stderr, err := pip.runCommand(PlannedStep{Description: "Install " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to install %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (pip *PipProvider) RemovePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	mode, err := pip.mode(pkgConfiguration)
+	if err != nil {
+		return
+	}
+
+	var removeArgs []string
+	if mode != pipModePipx {
+		removeArgs = append(removeArgs, "-y")
+	}
+	removeArgs = append(removeArgs, pkgConfiguration.Name)
+
+	name, args := pip.buildCommand(pkgConfiguration, mode, pip.RemoveCommand, removeArgs...)
+	stderr, err := pip.runCommand(PlannedStep{Description: "Remove " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to remove %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+// UpgradePackage runs pip install --upgrade in the user site or the virtual
+// environment, and pipx upgrade for pipx applications.
+func (pip *PipProvider) UpgradePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	mode, err := pip.mode(pkgConfiguration)
+	if err != nil {
+		return
+	}
+
+	subCommand := pip.UpgradeCommand
+	var upgradeArgs []string
+	if mode == pipModeUser {
+		upgradeArgs = append(upgradeArgs, "--user", "--upgrade")
+	} else if mode == pipModeVenv {
+		upgradeArgs = append(upgradeArgs, "--upgrade")
+	} else if mode == pipModePipx {
+		subCommand = "upgrade"
+	}
+	upgradeArgs = append(upgradeArgs, pkgConfiguration.Name)
+
+	name, args := pip.buildCommand(pkgConfiguration, mode, subCommand, upgradeArgs...)
+	stderr, err := pip.runCommand(PlannedStep{Description: "Upgrade " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to upgrade %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (pip *PipProvider) UpdateRegistry() (err error, cmdErr error) {
+	return
+}
+
+// CleanRegistry cleans the pip cache of the default interpreter, when it is
+// installed.
+func (pip *PipProvider) CleanRegistry() (err error, cmdErr error) {
+	if _, lookErr := pip.Executor.LookPath(defaultPythonInterpreter); lookErr != nil {
+		return
+	}
+
+	stderr, err := pip.runCommand(PlannedStep{Description: "Clean " + pip.Command + " registry"}, defaultPythonInterpreter, "-m", pip.Command, "cache", pip.CleanCommand)
+	if err != nil {
+		err = errors.New("failed to clean pip cache")
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+// IsAvailable checks for the default interpreter, pip being run as a module,
+// or for pipx. Whether the command of a given package is installed is told by
+// MissingCommand.
+func (pip *PipProvider) IsAvailable() bool {
+	return slices.ContainsFunc([]string{defaultPythonInterpreter, pipModePipx}, func(command string) bool {
+		_, err := pip.Executor.LookPath(command)
+		return err == nil
+	})
+}
+
+// MissingCommand checks for the command the mode of the package runs: pipx,
+// the python of its virtual environment, or the interpreter creating it or
+// running pip.
+func (pip *PipProvider) MissingCommand(pkgConfiguration *models.PackageConfiguration) string {
+	mode, err := pip.mode(pkgConfiguration)
+	if err != nil {
+		// The mode error is reported when the package is synchronized.
+		return ""
+	}
+
+	command := pip.interpreter(pkgConfiguration)
+	if mode == pipModePipx {
+		command = pipModePipx
+	} else if _, statErr := os.Stat(pip.venvPython(pkgConfiguration)); mode == pipModeVenv && statErr == nil {
+		return ""
+	}
+	if _, err = pip.Executor.LookPath(command); err != nil {
+		return command
+	}
+
+	return ""
+}
+
+func (pip *PipProvider) IsInstalled(pkgConfiguration *models.PackageConfiguration) (installed bool, err error) {
+	version, err := pip.InstalledVersion(pkgConfiguration)
+	installed = version != ""
+
+	return
+}
+
+func (pip *PipProvider) InstalledVersion(pkgConfiguration *models.PackageConfiguration) (version string, err error) {
+	mode, err := pip.mode(pkgConfiguration)
+	if err != nil {
+		return
+	}
+
+	if mode == pipModePipx {
+		return pip.pipxInstalledVersion(pkgConfiguration.Name)
+	}
+
+	name, args := pip.buildCommand(pkgConfiguration, mode, "show", pkgConfiguration.Name)
+	stdout, queryErr := pip.queryCommand(name, args...)
+	if queryErr != nil {
+		// pip show exits with status 1 when the package isn't installed, and the
+		// virtual environment may not have been created yet.
+		var exitErr *ExitError
+		_, statErr := os.Stat(pip.venvPython(pkgConfiguration))
+		if !errors.As(queryErr, &exitErr) && !(mode == pipModeVenv && errors.Is(statErr, os.ErrNotExist)) {
+			err = queryErr
+		}
+		return
+	}
+
+	for _, line := range strings.Split(string(stdout), "\n") {
+		if installedVersion, found := strings.CutPrefix(line, "Version:"); found {
+			version = strings.TrimSpace(installedVersion)
+			break
+		}
+	}
+
+	return
+}
+
+func (pip *PipProvider) pipxInstalledVersion(packageName string) (version string, err error) {
+	stdout, err := pip.queryCommand(pipModePipx, "list", "--json")
+	if err != nil {
+		return
+	}
+
+	var listOutput pipxListOutput
+	if err = json.Unmarshal(stdout, &listOutput); err != nil {
+		return
+	}
+	if venv, found := listOutput.Venvs[packageName]; found {
+		version = venv.Metadata.MainPackage.PackageVersion
+	}
+
+	return
+}
+
+// createVenv creates the virtual environment of the package when missing.
+func (pip *PipProvider) createVenv(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	if _, statErr := os.Stat(pip.venvPython(pkgConfiguration)); statErr == nil {
+		return
+	}
+
+	venvPath := expandHome(pkgConfiguration.Pip.Venv)
+	stderr, err := pip.runCommand(PlannedStep{Description: "Create virtual environment " + venvPath}, pip.interpreter(pkgConfiguration), "-m", "venv", venvPath)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to create virtual environment %s", venvPath))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+// mode returns the configured install mode, defaulting to a venv install when
+// a venv is set and to a user install otherwise.
+func (pip *PipProvider) mode(pkgConfiguration *models.PackageConfiguration) (mode string, err error) {
+	mode = pkgConfiguration.Pip.Mode
+	if mode == "" && pkgConfiguration.Pip.Venv != "" {
+		mode = pipModeVenv
+	} else if mode == "" {
+		mode = pipModeUser
+	}
+
+	if mode != pipModeUser && mode != pipModeVenv && mode != pipModePipx {
+		err = errors.New(fmt.Sprintf("Pip mode not supported for %s: %s", pkgConfiguration.Name, mode))
+	} else if mode == pipModeVenv && pkgConfiguration.Pip.Venv == "" {
+		err = errors.New(fmt.Sprintf("No venv path configured for %s", pkgConfiguration.Name))
+	}
+
+	return
+}
+
+func (pip *PipProvider) interpreter(pkgConfiguration *models.PackageConfiguration) string {
+	if pkgConfiguration.Pip.Interpreter != "" {
+		return pkgConfiguration.Pip.Interpreter
+	}
+
+	return defaultPythonInterpreter
+}
+
+func (pip *PipProvider) venvPython(pkgConfiguration *models.PackageConfiguration) string {
+	return filepath.Join(expandHome(pkgConfiguration.Pip.Venv), "bin", "python")
+}
+
+func (pip *PipProvider) buildCommand(pkgConfiguration *models.PackageConfiguration, mode string, subCommand string, options ...string) (name string, args []string) {
+	if mode == pipModePipx {
+		name = pipModePipx
+	} else {
+		name = pip.interpreter(pkgConfiguration)
+		if mode == pipModeVenv {
+			name = pip.venvPython(pkgConfiguration)
+		}
+		args = append(args, "-m", pip.Command)
+	}
+
+	if pip.RequiresRoot == true {
+		args = append([]string{name}, args...)
+		name = "sudo"
+	}
+
+	args = append(args, subCommand)
+
+	if len(options) > 0 {
+		args = append(args, options...)
+	}
+
+	return
+}
+
+// expandHome replaces a leading ~ with the user home directory.
+func expandHome(path string) string {
+	if rest, found := strings.CutPrefix(path, "~"); found {
+		if home, err := os.UserHomeDir(); err == nil {
+			return home + rest
+		}
+	}
+
+	return path
+}
+
+func NewPipProvider() *PipProvider {
+	return &PipProvider{
+		&AbstractProvider{
+			Command:          "pip",
+			InstallCommand:   "install",
+			RemoveCommand:    "uninstall",
+			UpdateCommand:    "",
+			UpgradeCommand:   "install",
+			CleanCommand:     "purge",
+			RequiresRoot:     false,
+			Executor:         NewExecExecutor(),
+			VersionSeparator: "==",
+		},
+	}
+}
@@ -22,11 +22,127 @@ THE SOFTWARE.
 
 package providers
 
+import (
+	"net/http"
+	"qrobcis/pkgsmanager/internal/models"
+)
+
+// PlannedStep describes one change a provider makes to the system: a command
+// to run and, when relevant, the file it writes or the URL it downloads.
+type PlannedStep struct {
+	Description string   `json:"description"`
+	Command     []string `json:"command,omitempty"`
+	File        string   `json:"file,omitempty"`
+	Content     string   `json:"content,omitempty"`
+	URL         string   `json:"url,omitempty"`
+}
+
 type AbstractProvider struct {
 	Command          string
 	InstallCommand   string
+	RemoveCommand    string
 	UpdateCommand    string
+	UpgradeCommand   string
 	CleanCommand     string
 	VersionSeparator string
 	RequiresRoot     bool
+	Lock             string
+	DryRun           bool
+	Executor         Executor
+	HTTPClient       *http.Client
+	plan             []PlannedStep
+}
+
+// IsAvailable tells whether the provider command is installed on the system.
+func (provider *AbstractProvider) IsAvailable() bool {
+	_, err := provider.Executor.LookPath(provider.Command)
+
+	return err == nil
+}
+
+// MissingCommand returns the command the package is managed with when it is
+// not installed on the system, or an empty string.
+func (provider *AbstractProvider) MissingCommand(pkgConfiguration *models.PackageConfiguration) string {
+	if !provider.IsAvailable() {
+		return provider.Command
+	}
+
+	return ""
+}
+
+// LockName returns the system-wide lock the provider commands take, such as
+// dpkg for apt and deb, so that only one command holding it may run at a
+// time. It is empty when they take none.
+func (provider *AbstractProvider) LockName() string {
+	return provider.Lock
+}
+
+// RunsAsRoot tells whether the commands managing the package run with sudo,
+// which may prompt for a password.
+func (provider *AbstractProvider) RunsAsRoot(pkgConfiguration *models.PackageConfiguration) bool {
+	return provider.RequiresRoot
+}
+
+// CanPinVersion tells whether the provider can install a given version of the
+// package, which is needed to install the versions of a lockfile.
+func (provider *AbstractProvider) CanPinVersion(pkgConfiguration *models.PackageConfiguration) bool {
+	return true
+}
+
+// PinsVersion tells whether the version written in the configuration is an
+// exact version, as opposed to latest or a channel the package follows.
+func (provider *AbstractProvider) PinsVersion(pkgConfiguration *models.PackageConfiguration) bool {
+	return pkgConfiguration.ConfiguredVersion != "" && pkgConfiguration.ConfiguredVersion != "latest"
+}
+
+// SetExecutor replaces the executor running the provider commands.
+func (provider *AbstractProvider) SetExecutor(executor Executor) {
+	provider.Executor = executor
+}
+
+// SetDryRun switches the provider to dry-run mode, where commands changing the
+// system are recorded instead of being run. Queries are still run.
+func (provider *AbstractProvider) SetDryRun(dryRun bool) {
+	provider.DryRun = dryRun
+}
+
+// TakePlan returns the steps recorded since the last call and resets them.
+func (provider *AbstractProvider) TakePlan() (plan []PlannedStep) {
+	plan = provider.plan
+	provider.plan = nil
+
+	return
+}
+
+func (provider *AbstractProvider) recordStep(step PlannedStep) {
+	provider.plan = append(provider.plan, step)
+}
+
+// runCommand runs a command changing the system and returns what it printed on
+// stderr. The step content, if any, is fed to the command standard input.
+// In dry-run mode the command is only recorded.
+func (provider *AbstractProvider) runCommand(step PlannedStep, name string, args ...string) (stderr string, err error) {
+	step.Command = append([]string{name}, args...)
+	if provider.DryRun {
+		provider.recordStep(step)
+		return
+	}
+
+	command := Command{Name: name, Args: args}
+	if step.Content != "" {
+		command.Stdin = []byte(step.Content)
+	}
+	_, stderr, err = provider.Executor.Run(command)
+
+	return
+}
+
+// queryCommand runs a read-only command and returns its standard output.
+// The error is returned alongside the output so callers can decide whether a
+// non-zero exit status, reported as an *ExitError, means "not installed" or a
+// real failure. Queries are run in dry-run mode too.
+func (provider *AbstractProvider) queryCommand(name string, args ...string) (stdout []byte, err error) {
+	stdout, _, err = provider.Executor.Run(Command{Name: name, Args: args})
+
+	return
 }
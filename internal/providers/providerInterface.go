@@ -23,11 +23,212 @@ THE SOFTWARE.
 package providers
 
 import (
+	"errors"
 	"qrobcis/pkgsmanager/internal/models"
+	"qrobcis/pkgsmanager/internal/types/provider"
+	"slices"
+	"strings"
 )
 
 type PackageProvider interface {
 	InstallPackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error)
+	RemovePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error)
+	UpgradePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error)
 	UpdateRegistry() (err error, cmdErr error)
 	CleanRegistry() (err error, cmdErr error)
+	IsInstalled(pkgConfiguration *models.PackageConfiguration) (installed bool, err error)
+	InstalledVersion(pkgConfiguration *models.PackageConfiguration) (version string, err error)
+	IsAvailable() bool
+	MissingCommand(pkgConfiguration *models.PackageConfiguration) string
+	LockName() string
+	RunsAsRoot(pkgConfiguration *models.PackageConfiguration) bool
+	CanPinVersion(pkgConfiguration *models.PackageConfiguration) bool
+	PinsVersion(pkgConfiguration *models.PackageConfiguration) bool
+	SetExecutor(executor Executor)
+	SetDryRun(dryRun bool)
+	TakePlan() (plan []PlannedStep)
+}
+
+// LockedVersion is what the lockfile records about an installed package.
+type LockedVersion struct {
+	Version      string
+	Repository   string
+	Architecture string
+}
+
+// Locker is implemented by providers that record more about an installed
+// package than the version it is compared with, such as the repository it was
+// installed from.
+type Locker interface {
+	LockPackage(pkgConfiguration *models.PackageConfiguration) (locked LockedVersion, err error)
+}
+
+// InstalledPackage is a package found on the system by a Lister, with the
+// version to write in the configuration to keep it as it is.
+type InstalledPackage struct {
+	Name    string
+	Version string
+}
+
+// Lister is implemented by providers that can list the packages installed on
+// the system, to import them in the configuration.
+type Lister interface {
+	ListInstalled() (packages []InstalledPackage, err error)
+}
+
+// RepositoryManager is implemented by providers installing packages from the
+// repositories declared in the configuration.
+type RepositoryManager interface {
+	// SyncRepositories writes the repositories that are missing or differ
+	// from the configuration, then deletes the ones written earlier whose
+	// name is not in referenced.
+	SyncRepositories(repositories []*models.Repository, referenced []string) (err error, cmdErr error)
+}
+
+// PreferencesManager is implemented by providers that can pin packages to a
+// release or a version.
+type PreferencesManager interface {
+	// SyncPreferences pins the packages that are missing their pin or whose
+	// pin differs from the configuration, then deletes the pins written
+	// earlier for packages whose name is not in referenced.
+	SyncPreferences(packages []*models.PackageConfiguration, referenced []string) (err error, cmdErr error)
+}
+
+// Holder is implemented by providers that can hold packages, so that upgrades
+// run outside of pkgsmanager leave them at their installed version.
+type Holder interface {
+	ListHeld() (names []string, err error)
+	// ListManagedHolds returns the packages held by pkgsmanager, as opposed
+	// to the ones held by other means.
+	ListManagedHolds() (names []string, err error)
+	HoldPackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error)
+	UnholdPackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error)
+}
+
+// SystemUpgrader is implemented by providers able to upgrade every package of
+// the system at once, beyond the packages of the configuration.
+type SystemUpgrader interface {
+	UpgradeSystem() (err error, cmdErr error)
+}
+
+// ShouldHold tells whether the package should be held, as its hold option
+// asks or, without one, when the configuration requests an exact version. A
+// package held by pkgsmanager, listed in managedHolds, should be released
+// once neither asks for the hold anymore. managed is false when the
+// configuration says nothing about the hold of the package.
+func ShouldHold(pkgConfiguration *models.PackageConfiguration, managedHolds []string) (hold bool, managed bool) {
+	if pkgConfiguration.Apt.Hold != nil {
+		return *pkgConfiguration.Apt.Hold, true
+	}
+	if pkgConfiguration.ConfiguredVersion != "" && pkgConfiguration.ConfiguredVersion != "latest" {
+		return true, true
+	}
+
+	return false, slices.Contains(managedHolds, pkgConfiguration.Name)
+}
+
+// SyncHold holds or unholds the package as its configuration asks, when its
+// provider is a Holder. changed tells whether the hold was changed.
+func SyncHold(packageProvider PackageProvider, pkgConfiguration *models.PackageConfiguration) (changed bool, err error, cmdErr error) {
+	holder, ok := packageProvider.(Holder)
+	if !ok {
+		return
+	}
+
+	managedHolds, err := holder.ListManagedHolds()
+	if err != nil {
+		return
+	}
+	hold, managed := ShouldHold(pkgConfiguration, managedHolds)
+	if !managed {
+		return
+	}
+
+	held, err := holder.ListHeld()
+	if err != nil {
+		return
+	}
+	if slices.Contains(held, pkgConfiguration.Name) == hold {
+		if !hold && slices.Contains(managedHolds, pkgConfiguration.Name) {
+			// The package was released by other means, the hold
+			// recorded by pkgsmanager is stale.
+			err, cmdErr = holder.UnholdPackage(pkgConfiguration)
+		}
+		return
+	}
+	changed = true
+	if hold {
+		err, cmdErr = holder.HoldPackage(pkgConfiguration)
+	} else {
+		err, cmdErr = holder.UnholdPackage(pkgConfiguration)
+	}
+
+	return
+}
+
+// LockPackage returns what the lockfile should record about an installed
+// package, from the provider Locker implementation when it has one.
+func LockPackage(packageProvider PackageProvider, pkgConfiguration *models.PackageConfiguration) (locked LockedVersion, err error) {
+	if locker, ok := packageProvider.(Locker); ok {
+		return locker.LockPackage(pkgConfiguration)
+	}
+	locked.Version, err = packageProvider.InstalledVersion(pkgConfiguration)
+
+	return
+}
+
+// VersionMatches tells whether installedVersion satisfies the version requested
+// in the package configuration. An empty or "latest" version matches anything.
+func VersionMatches(pkgConfiguration *models.PackageConfiguration, installedVersion string) bool {
+	wantedVersion := pkgConfiguration.Version
+	if wantedVersion == "" || wantedVersion == "latest" {
+		return true
+	}
+
+	// Snap versions are channels such as "edge" or "beta" and can't be
+	// compared with the version string reported by snap list.
+	if pkgConfiguration.Provider == provider.Snap {
+		return true
+	}
+
+	return strings.TrimPrefix(wantedVersion, "v") == strings.TrimPrefix(installedVersion, "v")
+}
+
+// ErrNotDownloaded is returned by a Versioner that can't tell the version a
+// package installs before its file is downloaded, which only sync does.
+var ErrNotDownloaded = errors.New("not downloaded yet")
+
+// Versioner is implemented by providers that know the version a package
+// installs without it being requested, such as the version of a .deb file.
+type Versioner interface {
+	WantedVersion(pkgConfiguration *models.PackageConfiguration) (version string, err error)
+}
+
+// VersionSatisfied tells whether installedVersion is the version the package
+// should have: the one its provider installs when it is a Versioner, the one
+// requested in its configuration otherwise.
+func VersionSatisfied(packageProvider PackageProvider, pkgConfiguration *models.PackageConfiguration, installedVersion string) (satisfied bool, err error) {
+	if versioner, ok := packageProvider.(Versioner); ok {
+		wantedVersion, err := versioner.WantedVersion(pkgConfiguration)
+		return err == nil && wantedVersion == installedVersion, err
+	}
+
+	return VersionMatches(pkgConfiguration, installedVersion), nil
+}
+
+// IsSatisfied tells whether the package is already installed at the version
+// requested in its configuration, in which case it doesn't need installing.
+func IsSatisfied(packageProvider PackageProvider, pkgConfiguration *models.PackageConfiguration) (satisfied bool, err error) {
+	installed, err := packageProvider.IsInstalled(pkgConfiguration)
+	if err != nil || !installed {
+		return
+	}
+
+	installedVersion, err := packageProvider.InstalledVersion(pkgConfiguration)
+	if err != nil {
+		return
+	}
+	satisfied, err = VersionSatisfied(packageProvider, pkgConfiguration, installedVersion)
+
+	return
 }
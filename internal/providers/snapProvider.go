@@ -23,11 +23,10 @@ THE SOFTWARE.
 package providers
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
-	"os/exec"
 	"qrobcis/pkgsmanager/internal/models"
+	"strings"
 )
 
 type SnapProvider struct {
@@ -43,14 +42,32 @@ func (snap *SnapProvider) InstallPackage(pkgConfiguration *models.PackageConfigu
 		args = append(args, "--classic", fmt.Sprintf("--%s", pkgConfiguration.Version))
 	}
 
-	cmd := exec.Command(name, args...)
-
-	errBuffer := new(bytes.Buffer)
-	cmd.Stderr = errBuffer
-	err = cmd.Run()
+	stderr, err := snap.runCommand(PlannedStep{Description: "Install " + pkgConfiguration.Name}, name, args...)
 	if err != nil {
 		err = errors.New(fmt.Sprintf("Failed to install %s", pkgConfiguration.Name))
-		cmdErr = errors.New(errBuffer.String())
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (snap *SnapProvider) RemovePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	name, args := snap.buildCommand(snap.RemoveCommand, false, pkgConfiguration.Name)
+	stderr, err := snap.runCommand(PlannedStep{Description: "Remove " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to remove %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (snap *SnapProvider) UpgradePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	name, args := snap.buildCommand(snap.UpgradeCommand, false, pkgConfiguration.Name)
+	stderr, err := snap.runCommand(PlannedStep{Description: "Upgrade " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to upgrade %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
 	}
 
 	return
@@ -66,6 +83,68 @@ func (snap *SnapProvider) CleanRegistry() (err error, cmdErr error) {
 	return
 }
 
+func (snap *SnapProvider) IsInstalled(pkgConfiguration *models.PackageConfiguration) (installed bool, err error) {
+	version, err := snap.InstalledVersion(pkgConfiguration)
+	installed = version != ""
+
+	return
+}
+
+func (snap *SnapProvider) InstalledVersion(pkgConfiguration *models.PackageConfiguration) (version string, err error) {
+	stdout, queryErr := snap.queryCommand(snap.Command, "list", pkgConfiguration.Name)
+	if queryErr != nil {
+		// snap list exits with status 1 when no matching snap is installed.
+		var exitErr *ExitError
+		if !errors.As(queryErr, &exitErr) {
+			err = queryErr
+		}
+		return
+	}
+
+	for _, line := range strings.Split(string(stdout), "\n")[1:] {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == pkgConfiguration.Name {
+			version = fields[1]
+			break
+		}
+	}
+
+	return
+}
+
+// CanPinVersion is false as the version of a snap is a channel, the revisions
+// it serves change over time.
+func (snap *SnapProvider) CanPinVersion(pkgConfiguration *models.PackageConfiguration) bool {
+	return false
+}
+
+// PinsVersion is false as the version of a snap is the channel it follows.
+func (snap *SnapProvider) PinsVersion(pkgConfiguration *models.PackageConfiguration) bool {
+	return false
+}
+
+// ListInstalled returns the installed snaps, with the risk level of the
+// channel they track as version, such as stable or edge. Bases and snapd are
+// dependencies of the other snaps and are left out.
+func (snap *SnapProvider) ListInstalled() (packages []InstalledPackage, err error) {
+	stdout, err := snap.queryCommand(snap.Command, "list")
+	if err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(string(stdout), "\n")[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 6 || fields[5] == "base" || fields[5] == "snapd" || fields[5] == "core" {
+			continue
+		}
+
+		tracking := strings.Split(fields[3], "/")
+		packages = append(packages, InstalledPackage{Name: fields[0], Version: tracking[len(tracking)-1]})
+	}
+
+	return
+}
+
 func (snap *SnapProvider) buildCommand(subCommand string, autoApprove bool, options ...string) (name string, args []string) {
 	name = snap.Command
 	if snap.RequiresRoot == true {
@@ -90,9 +169,13 @@ func NewSnapProvider() *SnapProvider {
 		&AbstractProvider{
 			Command:          "snap",
 			InstallCommand:   "install",
+			RemoveCommand:    "remove",
 			UpdateCommand:    "",
+			UpgradeCommand:   "refresh",
 			CleanCommand:     "",
 			RequiresRoot:     true,
+			Lock:             "snap",
+			Executor:         NewExecExecutor(),
 			VersionSeparator: "",
 		},
 	}
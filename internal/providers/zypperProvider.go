@@ -0,0 +1,138 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"qrobcis/pkgsmanager/internal/models"
+)
+
+type ZypperProvider struct {
+	*AbstractProvider
+}
+
+func (zypper *ZypperProvider) InstallPackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	packageNameVersionned := pkgConfiguration.Name
+	if pkgConfiguration.Version != "" {
+		packageNameVersionned = pkgConfiguration.Name + zypper.VersionSeparator + pkgConfiguration.Version
+	}
+
+	name, args := zypper.buildCommand(zypper.InstallCommand, packageNameVersionned)
+	stderr, err := zypper.runCommand(PlannedStep{Description: "Install " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to install %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (zypper *ZypperProvider) RemovePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	name, args := zypper.buildCommand(zypper.RemoveCommand, pkgConfiguration.Name)
+	stderr, err := zypper.runCommand(PlannedStep{Description: "Remove " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to remove %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (zypper *ZypperProvider) UpgradePackage(pkgConfiguration *models.PackageConfiguration) (err error, cmdErr error) {
+	name, args := zypper.buildCommand(zypper.UpgradeCommand, pkgConfiguration.Name)
+	stderr, err := zypper.runCommand(PlannedStep{Description: "Upgrade " + pkgConfiguration.Name}, name, args...)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Failed to upgrade %s", pkgConfiguration.Name))
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (zypper *ZypperProvider) UpdateRegistry() (err error, cmdErr error) {
+	name, args := zypper.buildCommand(zypper.UpdateCommand)
+	stderr, err := zypper.runCommand(PlannedStep{Description: "Update " + zypper.Command + " registry"}, name, args...)
+	if err != nil {
+		err = errors.New("failed to refresh zypper repositories")
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (zypper *ZypperProvider) CleanRegistry() (err error, cmdErr error) {
+	name, args := zypper.buildCommand(zypper.CleanCommand, "--all")
+	stderr, err := zypper.runCommand(PlannedStep{Description: "Clean " + zypper.Command + " registry"}, name, args...)
+	if err != nil {
+		err = errors.New("failed to clean zypper cache")
+		cmdErr = errors.New(stderr)
+	}
+
+	return
+}
+
+func (zypper *ZypperProvider) IsInstalled(pkgConfiguration *models.PackageConfiguration) (installed bool, err error) {
+	version, err := zypper.InstalledVersion(pkgConfiguration)
+	installed = version != ""
+
+	return
+}
+
+func (zypper *ZypperProvider) InstalledVersion(pkgConfiguration *models.PackageConfiguration) (version string, err error) {
+	return rpmInstalledVersion(zypper.AbstractProvider, pkgConfiguration)
+}
+
+// buildCommand puts the global --non-interactive option before the
+// sub-command, zypper refusing it afterwards.
+func (zypper *ZypperProvider) buildCommand(subCommand string, options ...string) (name string, args []string) {
+	name = zypper.Command
+	if zypper.RequiresRoot == true {
+		name = "sudo"
+		args = append(args, zypper.Command)
+	}
+	args = append(args, "--non-interactive", subCommand)
+
+	if len(options) > 0 {
+		args = append(args, options...)
+	}
+
+	return
+}
+
+func NewZypperProvider() *ZypperProvider {
+	return &ZypperProvider{
+		&AbstractProvider{
+			Command:          "zypper",
+			InstallCommand:   "install",
+			RemoveCommand:    "remove",
+			UpdateCommand:    "refresh",
+			UpgradeCommand:   "update",
+			CleanCommand:     "clean",
+			RequiresRoot:     true,
+			Lock:             "rpm",
+			Executor:         NewExecExecutor(),
+			VersionSeparator: "=",
+		},
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package system
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// Facts describe the machine the configuration is applied to, for the
+// conditions of groups and packages.
+type Facts struct {
+	Hostname     string
+	Release      OSRelease
+	Architecture string
+	LookupEnv    func(key string) (string, bool)
+	HasCommand   func(name string) bool
+}
+
+// CurrentFacts gathers the facts of the running machine. Facts that can't be
+// read are left empty and match no condition.
+func CurrentFacts() (facts Facts) {
+	facts.Hostname, _ = os.Hostname()
+	facts.Release, _ = ReadOSRelease()
+	facts.Architecture = runtime.GOARCH
+	facts.LookupEnv = os.LookupEnv
+	facts.HasCommand = func(name string) bool {
+		_, err := exec.LookPath(name)
+		return err == nil
+	}
+
+	return
+}
+
+// NormalizeArchitecture returns the Go name of a CPU architecture given under
+// its kernel or Debian name, such as x86_64 or armhf.
+func NormalizeArchitecture(architecture string) string {
+	switch architecture {
+	case "x86_64":
+		return "amd64"
+	case "aarch64":
+		return "arm64"
+	case "armhf", "armv7l", "armel":
+		return "arm"
+	case "i386", "i686", "x86":
+		return "386"
+	case "ppc64el":
+		return "ppc64le"
+	}
+
+	return architecture
+}
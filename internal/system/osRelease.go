@@ -0,0 +1,114 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package system
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"qrobcis/pkgsmanager/internal/types/provider"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var osReleasePaths = []string{"/etc/os-release", "/usr/lib/os-release"}
+
+// OSRelease holds the fields of os-release(5) used to identify the distribution.
+type OSRelease struct {
+	ID              string
+	IDLike          []string
+	VersionID       string
+	VersionCodename string
+}
+
+// ReadOSRelease reads the first os-release file found on the system.
+func ReadOSRelease() (release OSRelease, err error) {
+	for _, path := range osReleasePaths {
+		file, openErr := os.Open(path)
+		if openErr != nil {
+			continue
+		}
+		defer file.Close()
+
+		return parseOSRelease(bufio.NewScanner(file))
+	}
+
+	err = errors.New("no os-release file found")
+
+	return
+}
+
+func parseOSRelease(scanner *bufio.Scanner) (release OSRelease, err error) {
+	for scanner.Scan() {
+		key, value, found := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !found || strings.HasPrefix(key, "#") {
+			continue
+		}
+		if unquoted, unquoteErr := strconv.Unquote(value); unquoteErr == nil {
+			value = unquoted
+		} else {
+			value = strings.Trim(value, "'")
+		}
+
+		switch key {
+		case "ID":
+			release.ID = value
+		case "ID_LIKE":
+			release.IDLike = strings.Fields(value)
+		case "VERSION_ID":
+			release.VersionID = value
+		case "VERSION_CODENAME":
+			release.VersionCodename = value
+		}
+	}
+	err = scanner.Err()
+
+	return
+}
+
+// Is tells whether the distribution is id or derives from it.
+func (release OSRelease) Is(id string) bool {
+	return release.ID == id || slices.Contains(release.IDLike, id)
+}
+
+// NativeProvider returns the package manager of the distribution, checking
+// the distribution itself before the ones it derives from.
+func (release OSRelease) NativeProvider() provider.Provider {
+	for _, id := range append([]string{release.ID}, release.IDLike...) {
+		switch id {
+		case "debian", "ubuntu":
+			return provider.APT
+		case "fedora", "rhel", "centos":
+			return provider.DNF
+		case "arch":
+			return provider.Pacman
+		case "opensuse", "suse", "sles":
+			return provider.Zypper
+		case "alpine":
+			return provider.APK
+		}
+	}
+
+	return provider.Unknown
+}
@@ -25,29 +25,53 @@ package provider
 type Provider string
 
 const (
+    APK     Provider = "apk"
     APT     Provider = "apt"
+    Cargo   Provider = "cargo"
+    Deb     Provider = "deb"
+    DNF     Provider = "dnf"
+    Flatpak Provider = "flatpak"
     Gem     Provider = "gem"
     Golang  Provider = "go"
     NPM     Provider = "npm"
+    Pacman  Provider = "pacman"
     Pip     Provider = "pip"
     Unknown Provider = "Unknown"
     Unset   Provider = ""
     Snap    Provider = "snap"
+    System  Provider = "system"
+    Zypper  Provider = "zypper"
 )
 
 func ToProvider(providerName string) Provider {
-    if providerName == string(APT) {
+    if providerName == string(APK) {
+        return APK
+    } else if providerName == string(APT) {
         return APT
+    } else if providerName == string(Cargo) {
+        return Cargo
+    } else if providerName == string(Deb) {
+        return Deb
+    } else if providerName == string(DNF) {
+        return DNF
+    } else if providerName == string(Flatpak) {
+        return Flatpak
     } else if providerName == string(Gem) {
         return Gem
     } else if providerName == string(Golang) {
         return Golang
     } else if providerName == string(NPM) {
         return NPM
-        //    } else if providerName == string(Pip) {
-        //        return Pip
+    } else if providerName == string(Pacman) {
+        return Pacman
+    } else if providerName == string(Pip) {
+        return Pip
     } else if providerName == string(Snap) {
         return Snap
+    } else if providerName == string(System) {
+        return System
+    } else if providerName == string(Zypper) {
+        return Zypper
     } else if providerName == string(Unset) {
         return Unset
     } else {
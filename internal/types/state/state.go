@@ -0,0 +1,50 @@
+/*
+Copyright © 2025 Quentin ROBCIS
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package state
+
+type State string
+
+const (
+    Present State = "present"
+    Absent  State = "absent"
+    Purged  State = "purged"
+    Unknown State = "Unknown"
+    Unset   State = ""
+)
+
+func ToState(stateName string) State {
+    if stateName == string(Present) || stateName == string(Unset) {
+        return Present
+    } else if stateName == string(Absent) {
+        return Absent
+    } else if stateName == string(Purged) {
+        return Purged
+    } else {
+        return Unknown
+    }
+}
+
+// IsRemoved tells whether the state asks for the package to be uninstalled.
+func (state State) IsRemoved() bool {
+    return state == Absent || state == Purged
+}